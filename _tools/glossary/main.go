@@ -0,0 +1,141 @@
+// Command glossary generates the glossary page from the doc comments of the
+// SDK, thus the definitions always match the code.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SDKPackage is the import path prefix of the SDK packages.
+const SDKPackage = "gopkg.in/bblfsh/sdk.v1"
+
+// Sections is the list of SDK packages described in the glossary.
+var Sections = []struct {
+	Title   string
+	Package string
+}{
+	{Title: "UAST", Package: "uast"},
+	{Title: "Protocol", Package: "protocol"},
+	{Title: "Driver manifest", Package: "manifest"},
+}
+
+// RoleType is the name of the type of UAST roles. Roles are listed separately
+// since each of them is a separate term.
+const RoleType = "Role"
+
+var (
+	sdkDir = flag.String("sdk", "", "load the SDK from the source checkout in this directory instead of GOPATH")
+)
+
+const header = "" +
+	"<!-- Code generated by 'make glossary' DO NOT EDIT. -->\n\n" +
+	"# Glossary\n\n" +
+	"Definitions of the main Babelfish concepts, as documented in the SDK.\n"
+
+// Term is a single glossary entry.
+type Term struct {
+	Name string
+	Doc  string
+}
+
+func main() {
+	flag.Parse()
+
+	buf := bytes.NewBufferString(header)
+	for _, s := range Sections {
+		pkg, err := loadPackage(s.Package)
+		if err != nil {
+			log.Fatal(err)
+		}
+		terms, roles := packageTerms(pkg)
+		fmt.Fprintf(buf, "\n## %s\n", s.Title)
+		writeTerms(buf, terms)
+		if len(roles) != 0 {
+			buf.WriteString("\n### Roles\n\n" +
+				"See the [roles list](uast/roles.md) for the usage of the roles by each driver.\n\n")
+			for _, t := range roles {
+				fmt.Fprintf(buf, "- [%s](uast/roles.md#%s): %s\n",
+					t.Name, strings.ToLower(t.Name), doc.Synopsis(t.Doc))
+			}
+		}
+	}
+	os.Stdout.Write(buf.Bytes())
+}
+
+// loadPackage parses the SDK package with a given name, relative to the SDK
+// root.
+func loadPackage(name string) (*doc.Package, error) {
+	var dir string
+	if *sdkDir != "" {
+		dir = filepath.Join(*sdkDir, name)
+	} else {
+		p, err := build.Import(SDKPackage+"/"+name, "", build.FindOnly)
+		if err != nil {
+			return nil, err
+		}
+		dir = p.Dir
+	}
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := pkgs[name]
+	if !ok {
+		return nil, fmt.Errorf("package %s not found in %s", name, dir)
+	}
+	return doc.New(p, SDKPackage+"/"+name, 0), nil
+}
+
+// packageTerms returns documented exported types of the package, and the
+// roles if the package defines them.
+func packageTerms(pkg *doc.Package) (terms, roles []Term) {
+	for _, t := range pkg.Types {
+		if t.Doc == "" {
+			continue
+		}
+		terms = append(terms, Term{Name: t.Name, Doc: t.Doc})
+		if t.Name != RoleType {
+			continue
+		}
+		for _, v := range t.Consts {
+			for _, spec := range v.Decl.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if vs.Doc == nil {
+					continue
+				}
+				for _, id := range vs.Names {
+					if id.IsExported() {
+						roles = append(roles, Term{Name: id.Name, Doc: vs.Doc.Text()})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return terms, roles
+}
+
+func writeTerms(buf *bytes.Buffer, terms []Term) {
+	for _, t := range terms {
+		fmt.Fprintf(buf, "\n### %s\n\n", t.Name)
+		// doc comments are plain text, which is mostly valid Markdown
+		buf.WriteString(strings.TrimSpace(t.Doc) + "\n")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	checkpointFile = flag.String("checkpoint", filepath.Join(os.TempDir(), "languages.checkpoint.json"), "file to save the progress to, to be able to resume an interrupted run")
+	resume         = flag.Bool("resume", false, "skip drivers already processed by an interrupted run, as saved in the checkpoint file")
+)
+
+// checkpoint keeps the information about drivers that were already processed.
+// It is saved after each driver, thus an interrupted run can be resumed.
+type checkpoint struct {
+	path string
+
+	mu      sync.Mutex
+	drivers map[string]checkpointEntry
+}
+
+// checkpointEntry is a driver saved to the checkpoint.
+type checkpointEntry struct {
+	Driver Driver
+	// Details is set if the releases, commits and CI status were collected
+	// for the driver, see loader.details.
+	Details bool
+}
+
+// openCheckpoint creates a new checkpoint, or loads the existing one if the
+// run is resumed.
+func openCheckpoint(path string, resume bool) (*checkpoint, error) {
+	cp := &checkpoint{path: path, drivers: make(map[string]checkpointEntry)}
+	if !resume {
+		return cp, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cp.drivers); err != nil {
+		return nil, err
+	}
+	log.Println(len(cp.drivers), "drivers loaded from the checkpoint")
+	return cp, nil
+}
+
+// restore replaces the driver information with the one saved in the
+// checkpoint. Unexported fields are not saved, thus those of the given driver
+// are kept. It returns false if the driver was not processed yet, or if the
+// details are needed, but were not collected for it.
+func (cp *checkpoint) restore(d *Driver, details bool) bool {
+	if cp == nil {
+		return false
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	e, ok := cp.drivers[d.Language]
+	// the entries saved by older versions have no driver
+	if !ok || e.Driver.Language != d.Language || (details && !e.Details) {
+		return false
+	}
+	saved := e.Driver
+	saved.image, saved.sdkDeps = d.image, d.sdkDeps
+	saved.done = true
+	*d = saved
+	return true
+}
+
+// save adds the processed driver to the checkpoint and writes it to the file.
+// The details flag tells if the details were collected for the driver.
+func (cp *checkpoint) save(d Driver, details bool) error {
+	if cp == nil {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.drivers[d.Language] = checkpointEntry{Driver: d, Details: details}
+	data, err := json.Marshal(cp.drivers)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first to not corrupt the checkpoint
+	// if the process is killed; the name is unique, since concurrent
+	// runs may share the checkpoint
+	f, err := ioutil.TempFile(filepath.Dir(cp.path), filepath.Base(cp.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), cp.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// remove deletes the checkpoint file after a successful run.
+func (cp *checkpoint) remove() error {
+	if cp == nil {
+		return nil
+	}
+	err := os.Remove(cp.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
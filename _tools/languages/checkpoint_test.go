@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// checkpointDriver returns a driver with all the information that is saved
+// to the checkpoint.
+func checkpointDriver() Driver {
+	d := Driver{
+		Repository:   "https://github.com/bblfsh/python-driver",
+		DockerhubURL: "https://hub.docker.com/r/bblfsh/python-driver/",
+		SDKVersion:   "v1.16.1",
+		Release: &Release{
+			Tag:  "v2.9.0",
+			URL:  "https://github.com/bblfsh/python-driver/releases/tag/v2.9.0",
+			Date: time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		CI: &CIStatus{State: "success", URL: "https://github.com/bblfsh/python-driver/commits/master"},
+		Dependencies: []Dependency{
+			{Path: "gopkg.in/bblfsh/sdk.v1", Version: "v1.16.1"},
+			{Path: "github.com/stretchr/testify", Version: "v1.2.2"},
+		},
+		Stale: []string{"no commits for 14 months"},
+	}
+	d.Language = "python"
+	commit := time.Date(2019, 4, 2, 10, 0, 0, 0, time.UTC)
+	fixtures := time.Date(2019, 2, 5, 9, 30, 0, 0, time.UTC)
+	d.LastCommit, d.FixturesUpdated = &commit, &fixtures
+	cover := 72.5
+	d.TestCoverage = &cover
+	return d
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	cp, err := openCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := checkpointDriver()
+	saved.image = "saved/image"
+	if err := cp.save(saved, true); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err = openCheckpoint(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Driver{image: "bblfsh/python-driver", sdkDeps: []Dependency{{Path: "github.com/pkg/errors", Version: "v0.8.1"}}}
+	got.Language = "python"
+	fresh := got
+	if !cp.restore(&got, true) {
+		t.Fatal("the saved driver is not restored")
+	}
+	// unexported fields are kept, since they are not saved
+	want := checkpointDriver()
+	want.image, want.sdkDeps, want.done = fresh.image, fresh.sdkDeps, true
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected driver restored:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	other := Driver{}
+	other.Language = "java"
+	if cp.restore(&other, false) {
+		t.Error("the driver that was not saved is restored")
+	}
+	var none *checkpoint
+	if none.restore(&other, false) {
+		t.Error("the driver is restored without a checkpoint")
+	}
+}
+
+func TestCheckpointDetails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	cp, err := openCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	python := checkpointDriver()
+	java := checkpointDriver()
+	java.Language = "java"
+	// only the dependencies are collected without the details
+	java.Release, java.CI, java.LastCommit = nil, nil, nil
+	if err := cp.save(python, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.save(java, false); err != nil {
+		t.Fatal(err)
+	}
+	cp, err = openCheckpoint(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		lang    string
+		details bool
+		want    bool
+	}{
+		{lang: "python", details: true, want: true},
+		{lang: "python", details: false, want: true},
+		{lang: "java", details: true, want: false},
+		{lang: "java", details: false, want: true},
+	}
+	for _, tc := range cases {
+		d := Driver{}
+		d.Language = tc.lang
+		if got := cp.restore(&d, tc.details); got != tc.want {
+			t.Errorf("%s (details: %v): restored: %v, want %v", tc.lang, tc.details, got, tc.want)
+		}
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Errorf("expected only the checkpoint in the directory, got %d files", len(files))
+	}
+}
+
+// roundTripFunc implements http.RoundTripper with a function.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestLoadDriverIncomplete(t *testing.T) {
+	defer func(v time.Duration) { *timeout = v }(*timeout)
+	*timeout = 50 * time.Millisecond
+
+	cases := []struct {
+		name string
+		rt   roundTripFunc
+	}{
+		{
+			name: "failed",
+			rt: func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+		{
+			name: "timeout",
+			rt: func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "checkpoint")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "checkpoint.json")
+			cp, err := openCheckpoint(path, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := &loader{cli: &http.Client{Transport: tc.rt}}
+			d := Driver{Repository: "https://github.com/bblfsh/python-driver"}
+			d.Language = "python"
+			if l.loadDriver(context.Background(), cp, &d) {
+				t.Error("expected the requests to fail")
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Error("the incomplete driver is saved to the checkpoint")
+			}
+
+			// drivers without a repository have nothing to load
+			other := Driver{}
+			other.Language = "java"
+			if !l.loadDriver(context.Background(), cp, &other) {
+				t.Error("unexpected failure")
+			}
+			cp, err = openCheckpoint(path, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cp.restore(&other, false) {
+				t.Error("the complete driver is not saved to the checkpoint")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	githubAPI = "https://api.github.com"
+	githubRaw = "https://raw.githubusercontent.com"
+)
+
+// Release is a tagged release of a driver.
+type Release struct {
+	Tag  string    `json:"tag_name"`
+	URL  string    `json:"html_url"`
+	Date time.Time `json:"published_at"`
+}
+
+// githubRepo returns the "owner/name" of the repository given its GitHub URL.
+// It returns an empty string if the repository is not hosted on GitHub.
+func githubRepo(url string) string {
+	const prefix = "https://github.com/"
+	if !strings.HasPrefix(url, prefix) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(url, prefix), ".git")
+}
+
+func (l *loader) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return l.do(ctx, req)
+}
+
+// do sends the request and returns the response body if it succeeds.
+func (l *loader) do(ctx context.Context, req *http.Request) (io.ReadCloser, error) {
+	resp, err := l.cli.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: unexpected status: %s", req.URL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// latestRelease returns the latest release of a GitHub repository.
+func (l *loader) latestRelease(ctx context.Context, repo string) (*Release, error) {
+	rc, err := l.get(ctx, githubAPI+"/repos/"+repo+"/releases/latest")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var r Release
+	if err := json.NewDecoder(rc).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CIStatus is the state of the continuous integration checks of the latest
+// commit on the master branch.
+type CIStatus struct {
+	// State is one of success, failure, pending or none.
+	State string
+	// URL is the page with the checks of the commit.
+	URL string
+}
+
+// ciStatus returns the status of the CI checks of the latest commit on the
+// master branch of a GitHub repository. Both commit statuses (e.g. Travis)
+// and check runs (e.g. GitHub Actions) are supported.
+func (l *loader) ciStatus(ctx context.Context, repo string) (*CIStatus, error) {
+	st := &CIStatus{
+		State: "none",
+		URL:   "https://github.com/" + repo + "/commits/master",
+	}
+	rc, err := l.get(ctx, githubAPI+"/repos/"+repo+"/commits/master/status")
+	if err != nil {
+		return nil, err
+	}
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	err = json.NewDecoder(rc).Decode(&combined)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	if combined.TotalCount != 0 {
+		st.State = combined.State
+		return st, nil
+	}
+
+	rc, err = l.get(ctx, githubAPI+"/repos/"+repo+"/commits/master/check-runs")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var checks struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := json.NewDecoder(rc).Decode(&checks); err != nil {
+		return nil, err
+	}
+	for _, c := range checks.CheckRuns {
+		switch {
+		case c.Status != "completed":
+			st.State = "pending"
+		case c.Conclusion == "failure" || c.Conclusion == "timed_out" || c.Conclusion == "cancelled":
+			st.State = "failure"
+			return st, nil
+		case st.State == "none":
+			st.State = "success"
+		}
+	}
+	return st, nil
+}
+
+// Dependency is a Go module or a dep project a repository depends on.
+type Dependency struct {
+	Path    string
+	Version string
+}
+
+// dependencies returns the dependencies of a GitHub repository at a given
+// ref. Both go.mod and dep (Gopkg.lock) are supported. Only the direct
+// requirements are listed for go.mod, while Gopkg.lock lists all projects.
+func (l *loader) dependencies(ctx context.Context, repo, ref string) ([]Dependency, error) {
+	if rc, err := l.get(ctx, githubRaw+"/"+repo+"/"+ref+"/go.mod"); err == nil {
+		defer rc.Close()
+		return depsFromGoMod(rc)
+	}
+	rc, err := l.get(ctx, githubRaw+"/"+repo+"/"+ref+"/Gopkg.lock")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return depsFromGopkgLock(rc)
+}
+
+// sdkVersion returns the version of the SDK in the list of dependencies.
+func sdkVersion(deps []Dependency) (string, error) {
+	for _, d := range deps {
+		if isSDK(d.Path) {
+			return d.Version, nil
+		}
+	}
+	return "", fmt.Errorf("SDK is not listed in the dependencies")
+}
+
+// isSDK checks if the import path is one of the SDK major versions.
+func isSDK(path string) bool {
+	return strings.HasPrefix(path, "gopkg.in/bblfsh/sdk.v") ||
+		path == "github.com/bblfsh/sdk" ||
+		strings.HasPrefix(path, "github.com/bblfsh/sdk/v")
+}
+
+func depsFromGoMod(r io.Reader) ([]Dependency, error) {
+	var (
+		deps    []Dependency
+		inBlock bool
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inBlock:
+			continue
+		}
+		if strings.HasSuffix(line, "// indirect") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "//") {
+			deps = append(deps, Dependency{Path: fields[0], Version: fields[1]})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+func depsFromGopkgLock(r io.Reader) ([]Dependency, error) {
+	var (
+		deps []Dependency
+		cur  *Dependency
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			cur = nil
+			if line == "[[projects]]" {
+				deps = append(deps, Dependency{})
+				cur = &deps[len(deps)-1]
+			}
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 || cur == nil {
+			continue
+		}
+		key := strings.TrimSpace(line[:i])
+		val := strings.Trim(strings.TrimSpace(line[i+1:]), `"`)
+		switch key {
+		case "name":
+			cur.Path = val
+		case "version", "revision":
+			// prefer the tagged version over the revision
+			if key == "version" || cur.Version == "" {
+				cur.Version = val
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// sdkMajor returns the major version of the SDK given its version.
+// It returns an empty string if the version is not a semantic version,
+// e.g. a commit hash.
+func sdkMajor(vers string) string {
+	if !strings.HasPrefix(vers, "v") {
+		return ""
+	}
+	vers = vers[1:]
+	if i := strings.IndexAny(vers, ".-+"); i >= 0 {
+		vers = vers[:i]
+	}
+	return vers
+}
+
+// IsLegacy checks if the driver only supports the protocol v1, thus it has
+// no semantic mode.
+func (m Driver) IsLegacy() bool {
+	return sdkMajor(m.SDKVersion) == "1"
+}
+
+// protocols returns the list of protocol versions supported by the driver
+// given the version of the SDK it uses.
+func protocols(sdk string) string {
+	switch sdkMajor(sdk) {
+	case "":
+		return "-"
+	case "1":
+		return "v1"
+	default:
+		return "v1, v2"
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDepsFromGoMod(t *testing.T) {
+	cases := []struct {
+		name string
+		mod  string
+		want []Dependency
+	}{
+		{name: "no requirements", mod: "module github.com/bblfsh/go-driver\n", want: nil},
+		{
+			name: "single",
+			mod: "module github.com/bblfsh/go-driver\n\n" +
+				"require gopkg.in/bblfsh/sdk.v2 v2.16.0\n",
+			want: []Dependency{{Path: "gopkg.in/bblfsh/sdk.v2", Version: "v2.16.0"}},
+		},
+		{
+			name: "block",
+			mod: "module github.com/bblfsh/go-driver\n\n" +
+				"go 1.12\n\n" +
+				"require (\n" +
+				"\t// the SDK\n" +
+				"\tgithub.com/bblfsh/sdk/v3 v3.1.0\n" +
+				"\tgithub.com/pkg/errors v0.8.1 // indirect\n" +
+				"\tgithub.com/stretchr/testify v1.3.0\n" +
+				")\n\n" +
+				"replace github.com/pkg/errors => github.com/pkg/errors v0.8.0\n",
+			want: []Dependency{
+				{Path: "github.com/bblfsh/sdk/v3", Version: "v3.1.0"},
+				{Path: "github.com/stretchr/testify", Version: "v1.3.0"},
+			},
+		},
+		{
+			name: "exclude block",
+			mod: "module m\n\n" +
+				"exclude (\n\tgithub.com/pkg/errors v0.8.0\n)\n" +
+				"require github.com/pkg/errors v0.8.1\n",
+			want: []Dependency{{Path: "github.com/pkg/errors", Version: "v0.8.1"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := depsFromGoMod(strings.NewReader(tc.mod))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDepsFromGopkgLock(t *testing.T) {
+	cases := []struct {
+		name string
+		lock string
+		want []Dependency
+	}{
+		{name: "empty", lock: "", want: nil},
+		{
+			name: "projects",
+			lock: "# This file is autogenerated, do not edit; changes may be undone by the next 'dep ensure'.\n\n\n" +
+				"[[projects]]\n" +
+				"  digest = \"1:abc\"\n" +
+				"  name = \"github.com/pkg/errors\"\n" +
+				"  packages = [\".\"]\n" +
+				"  revision = \"645ef00459ed84a119197bfb8d8205042c6df63d\"\n" +
+				"  version = \"v0.8.0\"\n\n" +
+				"[[projects]]\n" +
+				"  branch = \"master\"\n" +
+				"  name = \"golang.org/x/net\"\n" +
+				"  packages = [\"context\"]\n" +
+				"  revision = \"1c05540f6879653db88113bc4a2b70aec4bd491f\"\n\n" +
+				"[solve-meta]\n" +
+				"  analyzer-name = \"dep\"\n" +
+				"  inputs-digest = \"abc\"\n",
+			want: []Dependency{
+				{Path: "github.com/pkg/errors", Version: "v0.8.0"},
+				// the revision is used for projects without a tagged version
+				{Path: "golang.org/x/net", Version: "1c05540f6879653db88113bc4a2b70aec4bd491f"},
+			},
+		},
+		{
+			// keys are sorted, thus the version comes after the revision
+			name: "version after revision",
+			lock: "[[projects]]\n" +
+				"  name = \"gopkg.in/bblfsh/sdk.v1\"\n" +
+				"  version = \"v1.16.1\"\n" +
+				"  revision = \"0123456789abcdef\"\n",
+			want: []Dependency{{Path: "gopkg.in/bblfsh/sdk.v1", Version: "v1.16.1"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := depsFromGopkgLock(strings.NewReader(tc.lock))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	gitlabHosts = flag.String("gitlab-hosts", "gitlab.com", "comma-separated list of GitLab hosts, including self-hosted instances, to collect the information about additional drivers from")
+)
+
+// gitlabProject returns the API URL of the project given its repository URL.
+// It returns an empty string if the repository is not hosted on any of the
+// known GitLab instances.
+func gitlabProject(repo string) string {
+	u, err := url.Parse(strings.TrimSuffix(repo, ".git"))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	for _, host := range strings.Split(*gitlabHosts, ",") {
+		if strings.TrimSpace(host) != u.Host {
+			continue
+		}
+		path := strings.Trim(u.Path, "/")
+		if path == "" {
+			return ""
+		}
+		return "https://" + u.Host + "/api/v4/projects/" + url.PathEscape(path)
+	}
+	return ""
+}
+
+// getGitlab is the same as get, but authenticates the request with the
+// GITLAB_TOKEN, if it is set.
+func (l *loader) getGitlab(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	return l.do(ctx, req)
+}
+
+// loadGitlabInfo fills the same information as loadGithubInfo for a driver
+// hosted on GitLab, given the API URL of the project. It returns false if
+// any of the requests failed.
+func (l *loader) loadGitlabInfo(ctx context.Context, d *Driver, project string) (ok bool) {
+	ok = true
+	if deps, err := l.gitlabDependencies(ctx, project, "master"); err != nil {
+		stats.inc("languages_gitlab_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the dependencies: %v", d.Language, err)
+	} else if vers, err := sdkVersion(deps); err != nil {
+		log.Printf("%s: cannot detect SDK version: %v", d.Language, err)
+	} else {
+		d.SDKVersion = vers
+		d.Dependencies = deps
+	}
+	if !l.details {
+		return ok
+	}
+	if r, err := l.gitlabLatestRelease(ctx, project); err != nil {
+		stats.inc("languages_gitlab_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the latest release: %v", d.Language, err)
+	} else {
+		d.Release = r
+	}
+	if t, err := l.gitlabLastCommit(ctx, project, ""); err != nil {
+		stats.inc("languages_gitlab_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the last commit: %v", d.Language, err)
+	} else {
+		d.LastCommit = t
+	}
+	if t, err := l.gitlabLastCommit(ctx, project, "fixtures"); err == nil {
+		d.FixturesUpdated = t
+	}
+	if st, err := l.gitlabCIStatus(ctx, project, d.Repository); err != nil {
+		stats.inc("languages_gitlab_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the CI status: %v", d.Language, err)
+	} else {
+		d.CI = st
+	}
+	return ok
+}
+
+// gitlabDependencies is the same as dependencies for a GitLab project.
+func (l *loader) gitlabDependencies(ctx context.Context, project, ref string) ([]Dependency, error) {
+	file := func(name string) string {
+		return project + "/repository/files/" + url.PathEscape(name) + "/raw?ref=" + url.QueryEscape(ref)
+	}
+	if rc, err := l.getGitlab(ctx, file("go.mod")); err == nil {
+		defer rc.Close()
+		return depsFromGoMod(rc)
+	}
+	rc, err := l.getGitlab(ctx, file("Gopkg.lock"))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return depsFromGopkgLock(rc)
+}
+
+// gitlabLatestRelease returns the latest release of a GitLab project.
+func (l *loader) gitlabLatestRelease(ctx context.Context, project string) (*Release, error) {
+	rc, err := l.getGitlab(ctx, project+"/releases?per_page=1")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var list []struct {
+		Tag   string    `json:"tag_name"`
+		Date  time.Time `json:"released_at"`
+		Links struct {
+			Self string `json:"self"`
+		} `json:"_links"`
+	}
+	if err := json.NewDecoder(rc).Decode(&list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no releases")
+	}
+	r := list[0]
+	return &Release{Tag: r.Tag, URL: r.Links.Self, Date: r.Date}, nil
+}
+
+// gitlabLastCommit is the same as lastCommit for a GitLab project.
+func (l *loader) gitlabLastCommit(ctx context.Context, project, path string) (*time.Time, error) {
+	u := project + "/repository/commits?ref_name=master&per_page=1"
+	if path != "" {
+		u += "&path=" + url.QueryEscape(path)
+	}
+	rc, err := l.getGitlab(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var list []struct {
+		Date time.Time `json:"committed_date"`
+	}
+	if err := json.NewDecoder(rc).Decode(&list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no commits")
+	}
+	return &list[0].Date, nil
+}
+
+// gitlabCIStatus returns the status of the latest pipeline on the master
+// branch of a GitLab project.
+func (l *loader) gitlabCIStatus(ctx context.Context, project, repo string) (*CIStatus, error) {
+	st := &CIStatus{
+		State: "none",
+		URL:   strings.TrimSuffix(repo, ".git") + "/-/pipelines?ref=master",
+	}
+	rc, err := l.getGitlab(ctx, project+"/pipelines?ref=master&per_page=1")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var list []struct {
+		Status string `json:"status"`
+		URL    string `json:"web_url"`
+	}
+	if err := json.NewDecoder(rc).Decode(&list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return st, nil
+	}
+	st.URL = list[0].URL
+	switch list[0].Status {
+	case "success":
+		st.State = "success"
+	case "failed", "canceled":
+		st.State = "failure"
+	case "skipped":
+	default:
+		// created, pending, running, manual, etc.
+		st.State = "pending"
+	}
+	return st, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// loadSDKDependencies fetches the dependencies of each SDK version used by
+// the drivers. Errors are logged, since only the dependency graph uses them.
+func (l *loader) loadSDKDependencies(ctx context.Context, list []Driver) {
+	deps := make(map[string][]Dependency)
+	for i := range list {
+		vers := list[i].SDKVersion
+		if vers == "" {
+			continue
+		}
+		if _, ok := deps[vers]; !ok {
+			d, err := l.dependencies(ctx, org+"/sdk", vers)
+			if err != nil {
+				stats.inc("languages_github_failures_total")
+				log.Printf("cannot get the dependencies of SDK %s: %v", vers, err)
+			}
+			deps[vers] = d
+		}
+		list[i].sdkDeps = deps[vers]
+	}
+}
+
+// Graph is the dependency graph of the drivers: drivers depend on the SDK
+// versions and on other modules, and SDK versions depend on modules as well.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a driver, an SDK version or a dependency.
+type GraphNode struct {
+	ID string `json:"id"`
+	// Kind is either "driver", "sdk" or "module".
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// GraphEdge points from the dependent node to its dependency.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// buildGraph returns the dependency graph of the drivers. Drivers with an
+// unknown SDK version are not included.
+func buildGraph(list []Driver) *Graph {
+	g := &Graph{}
+	seen := make(map[string]bool)
+	add := func(n GraphNode) string {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+		return n.ID
+	}
+	module := func(d Dependency) string {
+		return add(GraphNode{ID: "module:" + d.Path + "@" + d.Version, Kind: "module", Name: d.Path, Version: d.Version})
+	}
+	for _, d := range list {
+		if d.SDKVersion == "" {
+			continue
+		}
+		drv := add(GraphNode{ID: "driver:" + d.Language, Kind: "driver", Name: d.Language})
+		sdk := add(GraphNode{ID: "sdk:" + d.SDKVersion, Kind: "sdk", Name: "sdk", Version: d.SDKVersion})
+		g.Edges = append(g.Edges, GraphEdge{From: drv, To: sdk})
+		for _, dep := range d.Dependencies {
+			if !isSDK(dep.Path) {
+				g.Edges = append(g.Edges, GraphEdge{From: drv, To: module(dep)})
+			}
+		}
+		for _, dep := range d.sdkDeps {
+			g.Edges = append(g.Edges, GraphEdge{From: sdk, To: module(dep)})
+		}
+	}
+	// edges from the SDK are added once for each driver using it
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	edges := g.Edges[:0]
+	for i, e := range g.Edges {
+		if i == 0 || e != g.Edges[i-1] {
+			edges = append(edges, e)
+		}
+	}
+	g.Edges = edges
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	return g
+}
+
+func writeGraphJSON(w io.Writer, list []Driver) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(buildGraph(list))
+}
+
+// dotShapes are the Graphviz node shapes for each kind of the graph nodes.
+var dotShapes = map[string]string{
+	"driver": "box",
+	"sdk":    "ellipse",
+	"module": "note",
+}
+
+// writeDOT writes the dependency graph in the Graphviz format. Nodes of the
+// same kind are placed on the same rank.
+func writeDOT(w io.Writer, list []Driver) error {
+	g := buildGraph(list)
+	var buf strings.Builder
+	buf.WriteString("digraph drivers {\n\trankdir=LR;\n")
+	for _, kind := range []string{"driver", "sdk", "module"} {
+		fmt.Fprintf(&buf, "\tsubgraph %s {\n\t\trank=same;\n\t\tnode [shape=%s];\n", kind, dotShapes[kind])
+		for _, n := range g.Nodes {
+			if n.Kind != kind {
+				continue
+			}
+			label := n.Name
+			if n.Version != "" {
+				label += "\n" + n.Version
+			}
+			fmt.Fprintf(&buf, "\t\t%q [label=%q];\n", n.ID, label)
+		}
+		buf.WriteString("\t}\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\t%q -> %q;\n", e.From, e.To)
+	}
+	buf.WriteString("}\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildGraph(t *testing.T) {
+	sdkDeps := []Dependency{{Path: "github.com/pkg/errors", Version: "v0.8.1"}}
+	python := Driver{
+		SDKVersion: "v1.16.1",
+		Dependencies: []Dependency{
+			{Path: "gopkg.in/bblfsh/sdk.v1", Version: "v1.16.1"},
+			{Path: "github.com/stretchr/testify", Version: "v1.2.2"},
+		},
+		sdkDeps: sdkDeps,
+	}
+	python.Language = "python"
+	java := Driver{SDKVersion: "v1.16.1", sdkDeps: sdkDeps}
+	java.Language = "java"
+	// drivers with an unknown SDK version are not included
+	unknown := Driver{Dependencies: []Dependency{{Path: "github.com/pkg/errors", Version: "v0.8.0"}}}
+	unknown.Language = "ruby"
+
+	g := buildGraph([]Driver{python, java, unknown})
+	want := &Graph{
+		Nodes: []GraphNode{
+			{ID: "driver:java", Kind: "driver", Name: "java"},
+			{ID: "driver:python", Kind: "driver", Name: "python"},
+			{ID: "module:github.com/pkg/errors@v0.8.1", Kind: "module", Name: "github.com/pkg/errors", Version: "v0.8.1"},
+			{ID: "module:github.com/stretchr/testify@v1.2.2", Kind: "module", Name: "github.com/stretchr/testify", Version: "v1.2.2"},
+			{ID: "sdk:v1.16.1", Kind: "sdk", Name: "sdk", Version: "v1.16.1"},
+		},
+		Edges: []GraphEdge{
+			{From: "driver:java", To: "sdk:v1.16.1"},
+			{From: "driver:python", To: "module:github.com/stretchr/testify@v1.2.2"},
+			{From: "driver:python", To: "sdk:v1.16.1"},
+			// the SDK dependencies are listed once for all drivers using it
+			{From: "sdk:v1.16.1", To: "module:github.com/pkg/errors@v0.8.1"},
+		},
+	}
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("unexpected graph:\n%+v\nwant:\n%+v", g, want)
+	}
+}
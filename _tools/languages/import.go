@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/bblfsh/sdk.v1/manifest"
+	"gopkg.in/bblfsh/sdk.v1/manifest/discovery"
+)
+
+var reLink = regexp.MustCompile(`^\[(.*)\]\((.*)\)$`)
+
+// importMarkdown parses a languages report previously generated in Markdown
+// format and converts it back to the list of drivers.
+//
+// It allows to convert historical versions of languages.md to the same JSON
+// format as the one produced by "-o json".
+func importMarkdown(r io.Reader) ([]Driver, error) {
+	var (
+		list []Driver
+		line int
+		// column indexes by name, taken from the table header
+		cols map[string]int
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(s, "|") {
+			continue
+		}
+		cells := strings.Split(strings.Trim(s, "|"), "|")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		if cells[0] == "Language" {
+			cols = make(map[string]int)
+			for i, c := range cells {
+				cols[strings.Replace(c, `\*`, "", -1)] = i
+			}
+			continue
+		} else if strings.HasPrefix(cells[0], "---") {
+			continue
+		} else if cols == nil {
+			return nil, fmt.Errorf("line %d: table without a header", line)
+		} else if len(cells) != len(cols) {
+			return nil, fmt.Errorf("line %d: unexpected number of columns: %d", line, len(cells))
+		}
+		d, err := parseRow(cols, cells)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		list = append(list, d)
+	}
+	return list, sc.Err()
+}
+
+func parseRow(cols map[string]int, cells []string) (Driver, error) {
+	get := func(name string) string {
+		if i, ok := cols[name]; ok {
+			return cells[i]
+		}
+		return ""
+	}
+	var d Driver
+	d.Name, d.Repository = parseLink(get("Language"))
+	d.Language = get("Key")
+	if d.Language == "" {
+		return d, fmt.Errorf("empty language key")
+	}
+	d.Status = manifest.DevelopmentStatus(get("Status"))
+	if sdk := get("SDK"); sdk != "-" {
+		d.SDKVersion = sdk
+	}
+	for _, f := range []struct {
+		col  string
+		feat manifest.Feature
+	}{
+		{"AST", manifest.AST},
+		{"UAST", manifest.UAST},
+		{"Annotations", manifest.Roles},
+	} {
+		if ok, _ := parseLink(get(f.col)); ok == boolIcon(true) {
+			d.Features = append(d.Features, f.feat)
+		}
+	}
+	if _, url := parseLink(get("Container")); url != "" {
+		d.DockerhubURL = url
+	}
+	if name, url := parseLink(get("Maintainer")); name != "-" && name != "" {
+		m := discovery.Maintainer{Name: name}
+		if strings.HasPrefix(url, `https://github.com/`) {
+			m.Github = strings.TrimPrefix(url, `https://github.com/`)
+		} else if strings.HasPrefix(url, `mailto:`) {
+			m.Email = strings.TrimPrefix(url, `mailto:`)
+		}
+		d.Maintainers = append(d.Maintainers, m)
+	}
+	return d, nil
+}
+
+// parseLink is the reverse of link.
+func parseLink(s string) (name, url string) {
+	if sub := reLink.FindStringSubmatch(s); sub != nil {
+		return sub[1], sub[2]
+	}
+	return s, ""
+}
@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/heroku/docker-registry-client/registry"
-	"gopkg.in/bblfsh/sdk.v1/manifest"
 	"gopkg.in/bblfsh/sdk.v1/manifest/discovery"
 )
 
@@ -20,94 +25,250 @@ const (
 )
 
 var (
-	outFormat = flag.String("o", "md", "output format (md or json)")
+	outFormat  = flag.String("o", "md", "comma-separated list of output formats (md, html, status, json, dot or graph), each optionally followed by =path to write it to")
+	importFile = flag.String("import", "", "convert a previously generated Markdown report to JSON")
+	extraFile  = flag.String("extra", "", "JSON file with additional drivers hosted outside of the GitHub organization, e.g. on GitLab or on self-hosted Git servers")
+	timeout    = flag.Duration("timeout", time.Minute, "timeout for collecting the information about a single driver, including the waits for the GitHub rate limit reset")
+	proxy      = flag.String("proxy", "", "URL of the HTTP proxy to use instead of the one set by HTTPS_PROXY and HTTP_PROXY environment variables")
 )
 
 func main() {
 	flag.Parse()
+	base := http.DefaultTransport
+	if *proxy != "" {
+		u, err := url.Parse(*proxy)
+		if err != nil {
+			log.Fatalf("invalid proxy URL: %v", err)
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = http.ProxyURL(u)
+		base = t
+	}
+	http.DefaultTransport = &githubTransport{
+		base:  base,
+		token: os.Getenv("GITHUB_TOKEN"),
+	}
+	run := run
+	switch {
+	case flag.Arg(0) == "serve":
+		run = runServer
+	case flag.Arg(0) == "readme":
+		run = runReadme
+	case flag.Arg(0) != "":
+		log.Fatalf("unknown command: %s", flag.Arg(0))
+	case *importFile != "":
+		run = runImport
+	}
 	if err := run(os.Stdout); err != nil {
 		log.Fatal(err)
 	}
 }
 
+func runImport(w io.Writer) error {
+	f, err := os.Open(*importFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	list, err := importMarkdown(f)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, list)
+}
+
+func writeJSON(w io.Writer, list []Driver) error {
+	list = append([]Driver(nil), list...)
+	for i, d := range list {
+		if githubRepo(d.Repository) != "" {
+			list[i].GithubURL = d.Repository
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(list)
+}
+
 func run(w io.Writer) error {
-	ctx := context.TODO()
-	langs, err := discovery.OfficialDrivers(ctx, nil)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *pushgateway != "" {
+		// the metrics are pushed even if the run fails
+		defer func() {
+			if err := stats.push(*pushgateway, *pushJob); err != nil {
+				log.Printf("warning: cannot push metrics: %v", err)
+			}
+		}()
+	}
+	outs, err := parseOutputs(*outFormat)
 	if err != nil {
 		return err
 	}
+	cp, err := openCheckpoint(*checkpointFile, *resume)
+	if err != nil {
+		return err
+	}
+	list, err := loadDrivers(ctx, cp, needDetails(outs))
+	incomplete := false
+	if err != nil {
+		if ctx.Err() == nil || len(list) == 0 {
+			return err
+		}
+		// the second interrupt stops the process immediately
+		stop()
+		log.Printf("interrupted, writing a partial report for %d drivers", len(list))
+		incomplete = true
+	}
+	stats.set("languages_drivers", float64(len(list)))
+	if err := writeOutputs(w, outs, list, incomplete); err != nil {
+		return err
+	}
+	if !incomplete {
+		return cp.remove()
+	}
+	return nil
+}
+
+// loadDrivers discovers the drivers and collects the information about them.
+// Drivers saved in the checkpoint are not processed again. The checkpoint
+// may be nil. The releases, commits, CI status and the dependencies of the
+// SDK are only collected if details are requested, see needDetails.
+func loadDrivers(ctx context.Context, cp *checkpoint, details bool) ([]Driver, error) {
+	start := time.Now()
+	langs, err := discovery.OfficialDrivers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
 	names := make([]string, 0, len(langs))
 	for _, d := range langs {
 		names = append(names, d.Language)
 	}
 	log.Println(len(langs), "language drivers found:", names)
 
+	list := make([]Driver, len(langs))
+	for i, d := range langs {
+		list[i].Driver = d
+		list[i].Repository = d.RepositoryURL()
+		list[i].image = org + `/` + d.Language + `-driver`
+	}
+	if *extraFile != "" {
+		extra, err := loadExtraDrivers(*extraFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Println(len(extra), "additional drivers loaded")
+		list = append(list, extra...)
+		// keep the official drivers order, but move extra drivers
+		// to the right section of the table
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Status.Rank() > list[j].Status.Rank()
+		})
+	}
+
+	stats.since("languages_discovery_duration_seconds", start)
+
+	start = time.Now()
 	ld := newLoader()
+	ld.details = details
 
 	var (
-		list = make([]Driver, len(langs))
-
 		wg sync.WaitGroup
 		// limits the number of concurrent requests
 		tokens = make(chan struct{}, 3)
+		failed int32
 	)
-	for i, d := range langs {
-		list[i].Driver = d
-		list[i].GithubURL = d.RepositoryURL()
+	for i := range list {
 		wg.Add(1)
 		go func(d *Driver) {
 			defer wg.Done()
 
-			tokens <- struct{}{}
+			if cp.restore(d, details) {
+				return
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() {
 				<-tokens
 			}()
-
-			if name := org + `/` + d.Language + `-driver`; ld.checkDockerImage(name) {
-				d.DockerhubURL = `https://hub.docker.com/r/` + name + `/`
+			if !ld.loadDriver(ctx, cp, d) {
+				atomic.AddInt32(&failed, 1)
 			}
 		}(&list[i])
 	}
 	wg.Wait()
+	stats.since("languages_enrich_duration_seconds", start)
+	stats.set("languages_drivers_failed", float64(failed))
 
-	switch *outFormat {
-	case "json":
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "\t")
-		return enc.Encode(list)
-	case "md":
-		fallthrough
-	default:
-	}
-
-	fmt.Fprint(w, header)
-	defer fmt.Fprint(w, footer)
-
-	fmt.Fprintln(w, "\n# Supported languages")
-	fmt.Fprint(w, tableHeader)
-
-	li := len(list)
-	for i, m := range list {
-		if m.Status.Rank() < manifest.Alpha.Rank() {
-			li = i
-			break
+	if details {
+		sdk, err := ld.sdkReleases(ctx)
+		if err != nil {
+			log.Printf("cannot get the SDK releases: %v", err)
+		}
+		ld.loadSDKDependencies(ctx, list)
+		now := time.Now()
+		for i := range list {
+			list[i].Stale = staleReasons(list[i], sdk, now)
+			list[i].SDKUpgrade = sdkUpgrade(list[i], sdk)
 		}
-		fmt.Fprint(w, m.String())
 	}
 
-	list = list[li:]
-	if len(list) == 0 {
-		return nil
+	if err := ctx.Err(); err != nil {
+		// return drivers that were processed before the interruption
+		var done []Driver
+		for _, d := range list {
+			if d.done {
+				done = append(done, d)
+			}
+		}
+		return done, err
 	}
+	return list, nil
+}
 
-	fmt.Fprintln(w, "\n# In development")
-	fmt.Fprint(w, tableHeader)
+// detailFormats are the output formats that use the information collected
+// from the repository hosting besides the SDK version.
+var detailFormats = map[string]bool{
+	"status": true,
+	"json":   true,
+	"dot":    true,
+	"graph":  true,
+}
 
-	for _, m := range list {
-		fmt.Fprint(w, m.String())
+// needDetails checks if any of the outputs uses the releases, commits or
+// CI status of the drivers, which take a few more requests per driver.
+func needDetails(outs []output) bool {
+	for _, o := range outs {
+		if detailFormats[o.format] {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil
+// render writes the list of drivers in a given format. Incomplete reports
+// are marked as such, except for JSON and dependency graphs that have no
+// place for it. The serving flag is set if the report is served over HTTP,
+// thus the HTML report can be refreshed.
+func render(w io.Writer, format string, list []Driver, incomplete, serving bool) error {
+	switch format {
+	case "json":
+		return writeJSON(w, list)
+	case "status", "html":
+		return renderTemplate(w, format, list, incomplete, serving)
+	case "dot":
+		return writeDOT(w, list)
+	case "graph":
+		return writeGraphJSON(w, list)
+	case "md":
+		fallthrough
+	default:
+		return renderTemplate(w, "md", list, incomplete, serving)
+	}
 }
 
 func newLoader() *loader {
@@ -115,17 +276,176 @@ func newLoader() *loader {
 	if err != nil {
 		panic(err)
 	}
-	return &loader{r: r}
+	// registry client does not accept a context, thus only the timeout
+	// can be set for it
+	r.Client.Timeout = *timeout
+	return &loader{r: r, cli: http.DefaultClient}
 }
 
 type loader struct {
-	r *registry.Registry
+	r   *registry.Registry
+	cli *http.Client
+	// details is set if the releases, commits and CI status are needed
+	details bool
+}
+
+// loadDriver collects the information about the driver and saves it to the
+// checkpoint if it's complete, thus drivers with failed requests are retried
+// when the run is resumed. It returns false if any of the requests failed.
+func (l *loader) loadDriver(ctx context.Context, cp *checkpoint, d *Driver) bool {
+	dctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	if d.image != "" && l.checkDockerImage(d.image) {
+		d.DockerhubURL = `https://hub.docker.com/r/` + d.image + `/`
+	}
+	ok := true
+	if repo := githubRepo(d.Repository); repo != "" {
+		ok = l.loadGithubInfo(dctx, d, repo)
+	} else if project := gitlabProject(d.Repository); project != "" {
+		ok = l.loadGitlabInfo(dctx, d, project)
+	}
+	// the requests may have been cut short by the timeout
+	ok = ok && dctx.Err() == nil
+	complete := ok
+	if *testCoverDir != "" {
+		// tests are not limited by the timeout for the requests
+		if p, err := testCoverage(ctx, *testCoverDir, *d); err != nil {
+			stats.inc("languages_test_failures_total")
+			log.Printf("%s: cannot get the test coverage: %v", d.Language, err)
+			complete = false
+		} else {
+			d.TestCoverage = p
+		}
+	}
+	// the information is incomplete if the run was interrupted
+	d.done = ctx.Err() == nil
+	if d.done && complete {
+		if err := cp.save(*d, l.details); err != nil {
+			log.Printf("%s: cannot save the checkpoint: %v", d.Language, err)
+		}
+	}
+	return ok
+}
+
+// loadGithubInfo fills the dependencies, the SDK version, the latest release, the CI status and
+// the dates of the latest commits of a driver hosted on GitHub. Errors are logged, since the information is optional.
+// Only the dependencies are collected if the details are not needed. It returns false if any of the requests failed.
+func (l *loader) loadGithubInfo(ctx context.Context, d *Driver, repo string) (ok bool) {
+	ok = true
+	if deps, err := l.dependencies(ctx, repo, "master"); err != nil {
+		stats.inc("languages_github_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the dependencies: %v", d.Language, err)
+	} else if vers, err := sdkVersion(deps); err != nil {
+		log.Printf("%s: cannot detect SDK version: %v", d.Language, err)
+	} else {
+		d.SDKVersion = vers
+		d.Dependencies = deps
+	}
+	if !l.details {
+		return ok
+	}
+	if r, err := l.latestRelease(ctx, repo); err != nil {
+		stats.inc("languages_github_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the latest release: %v", d.Language, err)
+	} else {
+		d.Release = r
+	}
+	if t, err := l.lastCommit(ctx, repo, ""); err != nil {
+		stats.inc("languages_github_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the last commit: %v", d.Language, err)
+	} else {
+		d.LastCommit = t
+	}
+	if t, err := l.lastCommit(ctx, repo, "fixtures"); err == nil {
+		// older drivers have no fixtures directory
+		d.FixturesUpdated = t
+	}
+	if st, err := l.ciStatus(ctx, repo); err != nil {
+		stats.inc("languages_github_failures_total")
+		ok = false
+		log.Printf("%s: cannot get the CI status: %v", d.Language, err)
+	} else {
+		d.CI = st
+	}
+	return ok
 }
 
 type Driver struct {
 	discovery.Driver
+	// Repository is the URL of the driver repository. It may be hosted
+	// on GitHub or on any other Git hosting.
+	Repository string `json:",omitempty"`
+	// GithubURL is the same as Repository for drivers hosted on GitHub.
+	// It's only set in the JSON output, for the consumers that still
+	// expect it.
 	GithubURL    string `json:",omitempty"`
 	DockerhubURL string `json:",omitempty"`
+	// SDKVersion is the version of the SDK the driver depends on.
+	SDKVersion string   `json:",omitempty"`
+	Release    *Release `json:",omitempty"`
+	// Dependencies are the direct dependencies of the driver, including
+	// the SDK.
+	Dependencies []Dependency `json:",omitempty"`
+	// CI is the status of the checks on the master branch.
+	CI *CIStatus `json:",omitempty"`
+	// LastCommit is the date of the latest commit on the master branch.
+	LastCommit *time.Time `json:",omitempty"`
+	// FixturesUpdated is the date of the latest commit changing fixtures.
+	FixturesUpdated *time.Time `json:",omitempty"`
+	// Stale lists the reasons to consider the driver abandoned.
+	Stale []string `json:",omitempty"`
+	// SDKUpgrade lists the SDK releases the driver is missing.
+	SDKUpgrade *SDKUpgrade `json:",omitempty"`
+	// TestCoverage is the percentage of statements covered by the driver
+	// tests, if they were run.
+	TestCoverage *float64 `json:",omitempty"`
+
+	// image is the name of the driver image on Docker Hub
+	image string
+	// done is set when all the information about the driver is collected
+	done bool
+	// sdkDeps are the dependencies of the SDK version the driver uses
+	sdkDeps []Dependency
+}
+
+// extraDriver is an entry of the additional drivers file.
+type extraDriver struct {
+	discovery.Driver
+	// Repository is the URL of the driver repository.
+	Repository string
+	// Image is the name of the driver image on Docker Hub, if any.
+	Image string
+}
+
+// loadExtraDrivers reads a list of drivers that are not part of the official
+// GitHub organization, thus cannot be found by the discovery.
+func loadExtraDrivers(path string) ([]Driver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var extra []extraDriver
+	if err := json.NewDecoder(f).Decode(&extra); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	list := make([]Driver, 0, len(extra))
+	for _, d := range extra {
+		if d.Language == "" {
+			return nil, fmt.Errorf("%s: driver without a language", path)
+		}
+		list = append(list, Driver{
+			Driver:     d.Driver,
+			Repository: d.Repository,
+			image:      d.Image,
+		})
+	}
+	return list, nil
 }
 
 func (m Driver) Maintainer() discovery.Maintainer {
@@ -135,11 +455,16 @@ func (m Driver) Maintainer() discovery.Maintainer {
 	return m.Maintainers[0]
 }
 
-func (m Driver) String() string {
-	name := m.Name
-	if name == "" {
-		name = m.Language
+// DisplayName returns a human-readable name of the language.
+func (m Driver) DisplayName() string {
+	if m.Name == "" {
+		return m.Language
 	}
+	return m.Name
+}
+
+// MaintainerLink returns a Markdown link to the primary maintainer.
+func (m Driver) MaintainerLink() string {
 	mnt := m.Maintainer()
 	var mlink string
 	if mnt.Github != "" {
@@ -148,21 +473,19 @@ func (m Driver) String() string {
 	} else if mnt.Email != "" {
 		mlink = `mailto:` + mnt.Email
 	}
-	return fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s |\n",
-		link(name, m.GithubURL), m.Language, m.Status,
-		boolIcon(m.Supports(manifest.AST)),
-		boolIcon(m.Supports(manifest.UAST)),
-		boolIcon(m.Supports(manifest.Roles)),
-		linkMark(m.DockerhubURL),
-		link(mnt.Name, mlink),
-	)
+	return link(mnt.Name, mlink)
 }
 
 func (l *loader) checkDockerImage(name string) bool {
 	// dockerhub site always returns 200, even if repository does not exists
 	// so we will check image via Docker registry protocol
+	stats.inc("languages_docker_checks_total")
 	m, err := l.r.Manifest(name, "latest")
-	return err == nil && m != nil
+	if err != nil || m == nil {
+		stats.inc("languages_docker_missing_total")
+		return false
+	}
+	return true
 }
 
 func boolIcon(v bool) string {
@@ -185,20 +508,3 @@ func link(name, url string) string {
 	}
 	return fmt.Sprintf(`[%s](%s)`, name, url)
 }
-
-const header = `<!-- Code generated by 'make languages' DO NOT EDIT. -->
-`
-
-const tableHeader = `
-| Language   | Key        | Status  | AST\* | UAST\*\* | Annotations\*\*\* | Container | Maintainer |
-| ---------- | ---------- | ------- | ---- | ------ | -------------- | --------- | ---------- |
-`
-
-const footer = `
-- \* The driver is able to return the native AST
-- \*\* The driver is able to return the UAST
-- \*\*\* The driver is able to return the UAST annotated
-
-
-**Don't see your favorite language? [Help us!](community.md)**
-`
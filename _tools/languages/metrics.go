@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	pushgateway = flag.String("pushgateway", "", "URL of the Prometheus Pushgateway to push the metrics of the run to")
+	pushJob     = flag.String("push-job", "languages", "job name of the metrics pushed to the Pushgateway")
+)
+
+// metrics collects the statistics of driver reloads in serve mode, or of
+// a single run otherwise. They are exposed in Prometheus text format or
+// pushed to the Pushgateway to monitor scheduled report updates.
+type metrics struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+var stats = &metrics{values: make(map[string]float64)}
+
+// metricsHelp describes all exported metrics. Metrics with the "_total"
+// suffix are counters, the rest are gauges.
+var metricsHelp = map[string]string{
+	"languages_reloads_total":              "Number of driver list reloads.",
+	"languages_reload_failures_total":      "Number of failed driver list reloads.",
+	"languages_drivers":                    "Number of drivers found by the last reload.",
+	"languages_drivers_failed":             "Number of drivers with incomplete information because of failed requests during the last reload.",
+	"languages_docker_checks_total":        "Number of Docker Hub image checks.",
+	"languages_docker_missing_total":       "Number of Docker Hub images that were not found.",
+	"languages_github_failures_total":      "Number of failed requests for GitHub information.",
+	"languages_gitlab_failures_total":      "Number of failed requests for GitLab information.",
+	"languages_test_failures_total":        "Number of drivers with failed tests runs.",
+	"languages_discovery_duration_seconds": "Duration of the drivers discovery during the last reload.",
+	"languages_enrich_duration_seconds":    "Duration of collecting the drivers information during the last reload.",
+	"languages_render_duration_seconds":    "Duration of rendering the report in each format during the last run.",
+	"languages_last_reload_timestamp":      "Unix time of the last successful reload.",
+}
+
+func (m *metrics) inc(name string) {
+	m.mu.Lock()
+	m.values[name]++
+	m.mu.Unlock()
+}
+
+func (m *metrics) set(name string, v float64) {
+	m.mu.Lock()
+	m.values[name] = v
+	m.mu.Unlock()
+}
+
+// since sets the gauge to the number of seconds elapsed since a given time.
+func (m *metrics) since(name string, t time.Time) {
+	m.set(name, time.Since(t).Seconds())
+}
+
+// label returns the name of the metric series with a given label.
+func label(name, key, value string) string {
+	return fmt.Sprintf("%s{%s=%q}", name, key, value)
+}
+
+// write writes all metrics in Prometheus text format.
+func (m *metrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(metricsHelp))
+	for name := range metricsHelp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typ := "gauge"
+		if strings.HasSuffix(name, "_total") {
+			typ = "counter"
+		}
+		// series with labels are only written if there are any
+		var series []string
+		for key := range m.values {
+			if strings.HasPrefix(key, name+"{") {
+				series = append(series, key)
+			}
+		}
+		sort.Strings(series)
+		if len(series) == 0 {
+			series = []string{name}
+		}
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, metricsHelp[name], name, typ)
+		if err != nil {
+			return err
+		}
+		for _, key := range series {
+			if _, err := fmt.Fprintf(w, "%s %g\n", key, m.values[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// push replaces the metrics of the job in the Pushgateway with the metrics
+// of this run.
+func (m *metrics) push(addr, job string) error {
+	buf := bytes.NewBuffer(nil)
+	if err := m.write(buf); err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(addr, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequest("PUT", u, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway: unexpected status: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	stats.write(w)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// output is a single report to generate.
+type output struct {
+	format string
+	// path is the file to write the report to; standard output is used
+	// if it's empty
+	path string
+}
+
+// parseOutputs parses the list of outputs in form of "format[=path],...".
+// Only one of the outputs can be written to the standard output.
+func parseOutputs(s string) ([]output, error) {
+	var (
+		out    []output
+		stdout bool
+	)
+	for _, f := range strings.Split(s, ",") {
+		o := output{format: f}
+		if i := strings.Index(f, "="); i >= 0 {
+			o = output{format: f[:i], path: f[i+1:]}
+		}
+		if o.path == "" {
+			if stdout {
+				return nil, fmt.Errorf("only one output can be written to stdout, set the paths for others with format=path")
+			}
+			stdout = true
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// primaryFormat returns the first output format, which the -template flag
+// applies to.
+func primaryFormat() string {
+	f := strings.SplitN(*outFormat, ",", 2)[0]
+	return strings.SplitN(f, "=", 2)[0]
+}
+
+// writeOutputs renders the list of drivers in all output formats.
+func writeOutputs(w io.Writer, outs []output, list []Driver, incomplete bool) error {
+	for _, o := range outs {
+		// render to a buffer first to not leave a half-written report
+		buf := new(bytes.Buffer)
+		start := time.Now()
+		if err := render(buf, o.format, list, incomplete, false); err != nil {
+			return err
+		}
+		stats.since(label("languages_render_duration_seconds", "format", o.format), start)
+		var err error
+		if o.path == "" {
+			_, err = w.Write(buf.Bytes())
+		} else {
+			err = ioutil.WriteFile(o.path, buf.Bytes(), 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
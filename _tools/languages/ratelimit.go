@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRetries is the number of attempts made for a rate limited request.
+	maxRetries = 3
+	// maxBackoff limits the time to wait if the rate limit reset time is
+	// unknown or too far away.
+	maxBackoff = 5 * time.Minute
+)
+
+// githubTransport authenticates requests to the GitHub API and retries the
+// ones rejected because of the rate limit.
+//
+// The discovery client does not accept a token, but uses the default HTTP
+// transport, thus it is replaced globally to cover both the discovery and
+// the requests made by the tool.
+type githubTransport struct {
+	base  http.RoundTripper
+	token string
+}
+
+func (t *githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "api.github.com" {
+		return t.base.RoundTrip(req)
+	}
+	if t.token != "" {
+		// RoundTrip must not modify the request
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "token "+t.token)
+	}
+	for i := 0; ; i++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || i == maxRetries || !isRateLimited(resp) {
+			return resp, err
+		}
+		wait := rateLimitReset(resp, i)
+		resp.Body.Close()
+		// the requests of a driver are limited by -timeout, waiting longer
+		// would only end with the deadline error
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			log.Printf("GitHub rate limit exceeded, not retrying: the reset in %v is after the request deadline, try a larger -timeout", wait)
+			return nil, fmt.Errorf("GitHub rate limit exceeded, reset in %v", wait)
+		}
+		log.Printf("GitHub rate limit exceeded, retrying in %v", wait)
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// rateLimitReset returns the time to wait before retrying the request. It uses
+// the reset time reported by GitHub, or an exponential backoff if it's unknown.
+func rateLimitReset(resp *http.Response, attempt int) time.Duration {
+	wait := time.Duration(1<<uint(attempt)) * time.Minute
+	if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(sec) * time.Second
+	} else if ts, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		wait = time.Until(time.Unix(ts, 0)) + time.Second
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	} else if wait < time.Second {
+		wait = time.Second
+	}
+	return wait
+}
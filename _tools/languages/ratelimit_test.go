@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rateLimitResponse returns a response with a given status and headers.
+func rateLimitResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestIsRateLimited(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		want    bool
+	}{
+		{name: "ok", status: http.StatusOK, headers: map[string]string{"X-RateLimit-Remaining": "0"}, want: false},
+		{name: "primary", status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0"}, want: true},
+		{name: "secondary", status: http.StatusForbidden, headers: map[string]string{"Retry-After": "30"}, want: true},
+		{name: "too many requests", status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "30"}, want: true},
+		{name: "forbidden", status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "42"}, want: false},
+		{name: "not found", status: http.StatusNotFound, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRateLimited(rateLimitResponse(tc.status, tc.headers)); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	unix := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+	cases := []struct {
+		name     string
+		headers  map[string]string
+		attempt  int
+		min, max time.Duration
+	}{
+		{name: "backoff", attempt: 0, min: time.Minute, max: time.Minute},
+		{name: "backoff retry", attempt: 2, min: 4 * time.Minute, max: 4 * time.Minute},
+		{name: "backoff limit", attempt: 5, min: maxBackoff, max: maxBackoff},
+		{name: "retry after", headers: map[string]string{"Retry-After": "30"}, min: 30 * time.Second, max: 30 * time.Second},
+		{name: "retry after zero", headers: map[string]string{"Retry-After": "0"}, min: time.Second, max: time.Second},
+		{name: "reset", headers: map[string]string{"X-RateLimit-Reset": unix(2 * time.Minute)}, min: time.Minute, max: 2*time.Minute + 2*time.Second},
+		{name: "reset in the past", headers: map[string]string{"X-RateLimit-Reset": unix(-time.Hour)}, min: time.Second, max: time.Second},
+		{name: "reset far away", headers: map[string]string{"X-RateLimit-Reset": unix(time.Hour)}, min: maxBackoff, max: maxBackoff},
+		{
+			name:    "retry after first",
+			headers: map[string]string{"Retry-After": "10", "X-RateLimit-Reset": unix(time.Hour)},
+			min:     10 * time.Second, max: 10 * time.Second,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rateLimitReset(rateLimitResponse(http.StatusForbidden, tc.headers), tc.attempt)
+			if got < tc.min || got > tc.max {
+				t.Errorf("got %v, want from %v to %v", got, tc.min, tc.max)
+			}
+		})
+	}
+}
+
+func TestRateLimitDeadline(t *testing.T) {
+	calls := 0
+	tr := &githubTransport{base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "60"}), nil
+	})}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequest("GET", githubAPI+"/repos/bblfsh/python-driver/releases/latest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := tr.RoundTrip(req.WithContext(ctx)); err == nil {
+		t.Fatal("expected an error")
+	} else if !strings.Contains(err.Error(), "rate limit") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("waited for %v instead of failing", d)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single request, got %d", calls)
+	}
+}
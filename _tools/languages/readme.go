@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	readmeDir = flag.String("readme-dir", "drivers", "directory to write the driver pages to in readme mode")
+)
+
+const (
+	// excerptStart and excerptEnd mark the part of the driver README to
+	// include into the documentation. The whole README is used if there
+	// are no marks.
+	excerptStart = "<!-- docs:start -->"
+	excerptEnd   = "<!-- docs:end -->"
+)
+
+// linkRe matches Markdown links and images.
+var linkRe = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)([^)]*)\)`)
+
+// runReadme writes a documentation page with the README of each driver
+// hosted on GitHub.
+func runReadme(_ io.Writer) error {
+	ctx := context.Background()
+	list, err := loadDrivers(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*readmeDir, 0755); err != nil {
+		return err
+	}
+	ld := newLoader()
+	for _, d := range list {
+		repo := githubRepo(d.Repository)
+		if repo == "" {
+			continue
+		}
+		text, err := ld.readme(ctx, repo)
+		if err != nil {
+			log.Printf("%s: cannot get README: %v", d.Language, err)
+			continue
+		}
+		page := fmt.Sprintf("<!-- Code generated by 'make readmes' DO NOT EDIT. -->\n\n"+
+			"# %s\n\nFrom the [%s](%s) repository.\n\n%s\n",
+			d.DisplayName(), repo, d.Repository, rewriteLinks(excerpt(text), repo))
+		name := filepath.Join(*readmeDir, d.Language+".md")
+		if err := ioutil.WriteFile(name, []byte(page), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readme returns the README of a GitHub repository.
+func (l *loader) readme(ctx context.Context, repo string) (string, error) {
+	rc, err := l.get(ctx, githubRaw+"/"+repo+"/master/README.md")
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// excerpt returns the marked part of the README, or the whole README without
+// its title if there are no marks. The title is replaced by the page title.
+func excerpt(text string) string {
+	if i := strings.Index(text, excerptStart); i >= 0 {
+		text = text[i+len(excerptStart):]
+		if j := strings.Index(text, excerptEnd); j >= 0 {
+			text = text[:j]
+		}
+		return strings.TrimSpace(text)
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "# ") {
+		if i := strings.Index(text, "\n"); i >= 0 {
+			text = text[i+1:]
+		} else {
+			text = ""
+		}
+	}
+	// headings are shifted by one level, since the page has its own title
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rewriteLinks makes relative links of the README absolute, thus they point
+// to the files in the driver repository.
+func rewriteLinks(text, repo string) string {
+	return linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		img, title, url, rest := m[1], m[2], m[3], m[4]
+		if strings.Contains(url, "://") || strings.HasPrefix(url, "#") ||
+			strings.HasPrefix(url, "mailto:") {
+			return s
+		}
+		p := path.Clean(strings.TrimPrefix(url, "/"))
+		if img != "" {
+			url = githubRaw + "/" + repo + "/master/" + p
+		} else {
+			url = "https://github.com/" + repo + "/blob/master/" + p
+		}
+		return fmt.Sprintf("%s[%s](%s%s)", img, title, url, rest)
+	})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	httpAddr = flag.String("addr", ":8080", "address to listen on in serve mode")
+)
+
+// server keeps the list of drivers in memory and serves the reports.
+type server struct {
+	mu      sync.RWMutex
+	list    []Driver
+	updated time.Time
+
+	// refresh is held while the drivers are reloaded
+	refresh sync.Mutex
+}
+
+// runServer serves the reports over HTTP until the process is stopped.
+func runServer(_ io.Writer) error {
+	s := &server{}
+	if err := s.reload(context.Background()); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleFormat("html", "text/html; charset=utf-8"))
+	mux.HandleFunc("/languages.md", s.handleFormat("md", "text/markdown; charset=utf-8"))
+	mux.HandleFunc("/status.md", s.handleFormat("status", "text/markdown; charset=utf-8"))
+	mux.HandleFunc("/languages.json", s.handleFormat("json", "application/json"))
+	mux.HandleFunc("/refresh", s.handleRefresh)
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	log.Println("listening on", *httpAddr)
+	return http.ListenAndServe(*httpAddr, withRequestID(mux))
+}
+
+func (s *server) reload(ctx context.Context) error {
+	s.refresh.Lock()
+	defer s.refresh.Unlock()
+
+	stats.inc("languages_reloads_total")
+	list, err := loadDrivers(ctx, nil, true)
+	if err != nil {
+		stats.inc("languages_reload_failures_total")
+		return err
+	}
+
+	s.mu.Lock()
+	s.list, s.updated = list, time.Now()
+	s.mu.Unlock()
+
+	stats.set("languages_drivers", float64(len(list)))
+	stats.set("languages_last_reload_timestamp", float64(s.updated.Unix()))
+	return nil
+}
+
+func (s *server) handleFormat(format, typ string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if format == "html" && r.URL.Path != "/" {
+			writeError(w, r, http.StatusNotFound, fmt.Errorf("no report at %s", r.URL.Path))
+			return
+		}
+		s.mu.RLock()
+		list, updated := s.list, s.updated
+		s.mu.RUnlock()
+
+		// render to a buffer first to be able to report an error
+		buf := new(bytes.Buffer)
+		if err := render(buf, format, list, false, true); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", typ)
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+		w.Write(buf.Bytes())
+	}
+}
+
+func (s *server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, http.StatusMethodNotAllowed, errors.New("use POST to refresh the report"))
+		return
+	}
+	if err := s.reload(r.Context()); err != nil {
+		writeError(w, r, http.StatusBadGateway, err)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// requestIDHeader is the header with the ID of the request. A valid ID set
+// by the client or a proxy is kept, thus the logs can be correlated with theirs.
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// withRequestID assigns an ID to each request, returns it in the response
+// header and logs the request with it.
+func withRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+		start := time.Now()
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		log.Printf("[%s] %s %s (%v)", id, r.Method, r.URL.Path, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// validRequestID checks that the ID is short and only has the characters
+// safe to write to the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the ID of the request, see withRequestID.
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// errorBody is the body of the error responses.
+type errorBody struct {
+	Status    int    `json:"status"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id"`
+}
+
+// writeError logs the error with the request ID and writes it as JSON.
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	id := requestID(r)
+	log.Printf("[%s] %s %s: %v", id, r.Method, r.URL.Path, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Status: status, Error: err.Error(), RequestID: id})
+}
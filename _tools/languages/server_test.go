@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "generated"},
+		{name: "client", header: "abc-123", keep: true},
+		{name: "invalid", header: "a\nb"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			h := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, http.StatusBadGateway, errors.New("failed"))
+			}))
+			req := httptest.NewRequest("GET", "/", nil)
+			if c.header != "" {
+				req.Header.Set(requestIDHeader, c.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestIDHeader)
+			if c.keep && id != c.header {
+				t.Errorf("expected request ID %q, got %q", c.header, id)
+			} else if !c.keep && (id == "" || id == c.header) {
+				t.Errorf("expected a generated request ID, got %q", id)
+			}
+			if rec.Code != http.StatusBadGateway {
+				t.Errorf("unexpected status: %d", rec.Code)
+			}
+			var body errorBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			exp := errorBody{Status: http.StatusBadGateway, Error: "failed", RequestID: id}
+			if body != exp {
+				t.Errorf("expected %+v, got %+v", exp, body)
+			}
+		})
+	}
+}
+
+func TestRefreshForm(t *testing.T) {
+	for _, serving := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		if err := render(buf, "html", nil, false, serving); err != nil {
+			t.Fatal(err)
+		}
+		if has := strings.Contains(buf.String(), `action="/refresh"`); has != serving {
+			t.Errorf("serving: %v, refresh form rendered: %v", serving, has)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var (
+	staleAfter    = flag.Duration("stale-after", 180*24*time.Hour, "flag drivers without commits for this long as stale")
+	staleFixtures = flag.Duration("stale-fixtures", 365*24*time.Hour, "flag drivers with fixtures unchanged for this long as stale")
+	sdkLag        = flag.Int("sdk-lag", 5, "flag drivers using an SDK more than this number of releases behind the latest one as stale")
+)
+
+// lastCommit returns the date of the latest commit on the master branch of
+// a GitHub repository. If the path is set, only commits that change it are
+// considered.
+func (l *loader) lastCommit(ctx context.Context, repo, path string) (*time.Time, error) {
+	q := url.Values{"sha": {"master"}, "per_page": {"1"}}
+	if path != "" {
+		q.Set("path", path)
+	}
+	rc, err := l.get(ctx, githubAPI+"/repos/"+repo+"/commits?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var commits []struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := json.NewDecoder(rc).Decode(&commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return &commits[0].Commit.Committer.Date, nil
+}
+
+// SDKRelease is a release of the SDK with its release notes.
+type SDKRelease struct {
+	Release
+	Notes string `json:"body"`
+}
+
+// sdkReleases returns the SDK releases, the latest first.
+func (l *loader) sdkReleases(ctx context.Context) ([]SDKRelease, error) {
+	rc, err := l.get(ctx, githubAPI+"/repos/"+org+"/sdk/releases?per_page=100")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var list []SDKRelease
+	if err := json.NewDecoder(rc).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// staleReasons returns the reasons to consider the driver abandoned, or nil
+// if it's maintained. The SDK releases may be nil if they are unknown.
+func staleReasons(d Driver, sdk []SDKRelease, now time.Time) []string {
+	var reasons []string
+	if d.LastCommit != nil && now.Sub(*d.LastCommit) > *staleAfter {
+		reasons = append(reasons, "no commits since "+d.LastCommit.Format("2006-01-02"))
+	}
+	if d.FixturesUpdated != nil && now.Sub(*d.FixturesUpdated) > *staleFixtures {
+		reasons = append(reasons, "fixtures unchanged since "+d.FixturesUpdated.Format("2006-01-02"))
+	}
+	if i := releasesBehind(d, sdk); i > *sdkLag {
+		reasons = append(reasons, fmt.Sprintf("SDK is %d releases behind", i))
+	}
+	return reasons
+}
+
+// releasesBehind returns the number of SDK releases published after the one
+// the driver depends on, or -1 if the version is not one of the releases.
+func releasesBehind(d Driver, sdk []SDKRelease) int {
+	for i, r := range sdk {
+		if r.Tag == d.SDKVersion {
+			return i
+		}
+	}
+	return -1
+}
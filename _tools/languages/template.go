@@ -0,0 +1,131 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"gopkg.in/bblfsh/sdk.v1/manifest"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var (
+	tmplFile = flag.String("template", "", "template file to render the first output format with, instead of the built-in one")
+)
+
+var funcs = template.FuncMap{
+	"link":      link,
+	"linkMark":  linkMark,
+	"mark":      boolIcon,
+	"protocols": protocols,
+	"join":      strings.Join,
+	"percent":   percent,
+}
+
+// reportData is passed to the output templates.
+type reportData struct {
+	// Drivers is the list of all drivers.
+	Drivers []Driver
+	// Supported is the list of drivers with alpha status or better.
+	Supported []Driver
+	// InDevelopment is the list of drivers with a lower status.
+	InDevelopment []Driver
+	// Stale is the list of drivers that may be abandoned.
+	Stale []Driver
+	// Upgrade is the list of drivers depending on an outdated SDK release,
+	// the most urgent upgrades first.
+	Upgrade []Driver
+	// Current is the list of drivers that support the protocol v2, or
+	// drivers with an unknown SDK version.
+	Current []Driver
+	// Legacy is the list of drivers that only support the protocol v1.
+	Legacy []Driver
+	// Incomplete is set if the run was interrupted and the report
+	// lists only a part of the drivers.
+	Incomplete bool
+	// Serving is set if the report is served by the HTTP server, thus it
+	// can be refreshed.
+	Serving bool
+}
+
+func newReportData(list []Driver, incomplete, serving bool) reportData {
+	li := len(list)
+	for i, m := range list {
+		if m.Status.Rank() < manifest.Alpha.Rank() {
+			li = i
+			break
+		}
+	}
+	var stale, current, legacy []Driver
+	for _, d := range list {
+		if len(d.Stale) != 0 {
+			stale = append(stale, d)
+		}
+		if d.IsLegacy() {
+			legacy = append(legacy, d)
+		} else {
+			current = append(current, d)
+		}
+	}
+	return reportData{
+		Drivers:       list,
+		Supported:     list[:li],
+		InDevelopment: list[li:],
+		Stale:         stale,
+		Upgrade:       upgradePriority(list),
+		Current:       current,
+		Legacy:        legacy,
+		Incomplete:    incomplete,
+		Serving:       serving,
+	}
+}
+
+// templateFiles maps output formats to the built-in templates.
+var templateFiles = map[string]string{
+	"md":     "languages.md.tmpl",
+	"html":   "languages.html.tmpl",
+	"status": "status.md.tmpl",
+}
+
+// executor is implemented by both text and HTML templates.
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// loadTemplate loads a built-in template for the output format, or the one
+// specified by the user if the format is the one selected with -o.
+func loadTemplate(format string) (executor, error) {
+	name := templateFiles[format]
+	var (
+		data []byte
+		err  error
+	)
+	if *tmplFile != "" && format == primaryFormat() {
+		data, err = ioutil.ReadFile(*tmplFile)
+	} else {
+		data, err = templates.ReadFile("templates/" + name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, ".html.tmpl") {
+		// HTML templates escape the content
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Parse(string(data))
+	}
+	return template.New(name).Funcs(funcs).Parse(string(data))
+}
+
+// renderTemplate renders the list of drivers in a given format.
+func renderTemplate(w io.Writer, format string, list []Driver, incomplete, serving bool) error {
+	t, err := loadTemplate(format)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, newReportData(list, incomplete, serving))
+}
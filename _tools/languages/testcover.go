@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	testCoverDir = flag.String("test-cover", "", "directory with the driver clones (as the -repos-dir of the roles tool) to run the driver tests with coverage in")
+	testTimeout  = flag.Duration("test-timeout", 10*time.Minute, "timeout for running the tests of a single driver")
+)
+
+// testCoverage runs the tests of the driver cloned to the directory and
+// returns the percentage of the statements covered by them. Drivers without
+// a clone are skipped with a nil result.
+func testCoverage(ctx context.Context, root string, d Driver) (*float64, error) {
+	repo := githubRepo(d.Repository)
+	if repo == "" {
+		return nil, nil
+	}
+	dir := filepath.Join(root, filepath.FromSlash(repo))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "cover-"+d.Language)
+	if err != nil {
+		return nil, err
+	}
+	profile := f.Name()
+	f.Close()
+	defer os.Remove(profile)
+
+	ctx, cancel := context.WithTimeout(ctx, *testTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "test", "-coverprofile="+profile, "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("tests timed out after %v", *testTimeout)
+		}
+		// failing tests still report the coverage, but it's misleading
+		return nil, fmt.Errorf("go test: %v: %s", err, lastLine(out))
+	}
+	f, err = os.Open(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := parseCoverProfile(f)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// parseCoverProfile returns the percentage of the statements covered in the
+// Go coverage profile. Blocks listed multiple times are counted once.
+func parseCoverProfile(r io.Reader) (float64, error) {
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := make(map[string]block)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("invalid coverage profile line: %q", line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid coverage profile line: %q", line)
+		}
+		b := blocks[fields[0]]
+		b.stmts = stmts
+		b.covered = b.covered || fields[2] != "0"
+		blocks[fields[0]] = b
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	var total, covered int
+	for _, b := range blocks {
+		total += b.stmts
+		if b.covered {
+			covered += b.stmts
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return 100 * float64(covered) / float64(total), nil
+}
+
+// lastLine returns the last non-empty line of the command output, which is
+// usually the most relevant one.
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return lines[len(lines)-1]
+}
+
+// percent formats the optional percentage for the reports.
+func percent(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *p)
+}
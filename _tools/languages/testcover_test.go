@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCoverProfile(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile string
+		want    float64
+		err     bool
+	}{
+		{name: "empty", profile: "mode: set\n", want: 0},
+		{
+			name: "covered",
+			profile: "mode: set\n" +
+				"a.go:1.1,3.2 3 1\n" +
+				"a.go:4.1,5.2 1 0\n",
+			want: 75,
+		},
+		{
+			name: "none covered",
+			profile: "mode: count\n" +
+				"a.go:1.1,3.2 2 0\n" +
+				"b.go:1.1,3.2 2 0\n",
+			want: 0,
+		},
+		{
+			// the same block is listed for each package of a merged profile
+			name: "duplicate blocks",
+			profile: "mode: atomic\n" +
+				"a.go:1.1,3.2 2 0\n" +
+				"a.go:1.1,3.2 2 5\n" +
+				"a.go:4.1,5.2 2 0\n" +
+				"a.go:4.1,5.2 2 0\n",
+			want: 50,
+		},
+		{name: "missing count", profile: "mode: set\na.go:1.1,3.2 3\n", err: true},
+		{name: "invalid statements", profile: "mode: set\na.go:1.1,3.2 x 1\n", err: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCoverProfile(strings.NewReader(tc.profile))
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %v%%, want %v%%", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// SDKUpgrade describes the SDK releases a driver is missing.
+type SDKUpgrade struct {
+	// Latest is the tag of the latest SDK release.
+	Latest string
+	// Behind is the number of SDK releases published after the one the
+	// driver depends on.
+	Behind int
+	// Missing is the list of the releases published after the one the
+	// driver depends on, the latest first.
+	Missing []MissingRelease
+}
+
+// MissingRelease is an SDK release with the entries of its changelog.
+type MissingRelease struct {
+	Release
+	Changes []string `json:",omitempty"`
+}
+
+// sdkUpgrade returns the SDK releases the driver is missing, or nil if it
+// depends on the latest release or on an unknown version.
+func sdkUpgrade(d Driver, sdk []SDKRelease) *SDKUpgrade {
+	n := releasesBehind(d, sdk)
+	if n <= 0 {
+		return nil
+	}
+	u := &SDKUpgrade{Latest: sdk[0].Tag, Behind: n}
+	for _, r := range sdk[:n] {
+		u.Missing = append(u.Missing, MissingRelease{
+			Release: r.Release,
+			Changes: changelogEntries(r.Notes),
+		})
+	}
+	return u
+}
+
+// changelogEntries returns the list items of the release notes, without
+// the list markers. Headings and paragraphs are skipped.
+func changelogEntries(notes string) []string {
+	var out []string
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range []string{"- ", "* ", "+ "} {
+			if strings.HasPrefix(line, marker) {
+				out = append(out, strings.TrimSpace(line[len(marker):]))
+				break
+			}
+		}
+	}
+	return out
+}
+
+// upgradePriority orders the drivers that need an SDK upgrade: drivers lagging
+// behind the most go first, and the more mature ones first among those.
+func upgradePriority(list []Driver) []Driver {
+	var out []Driver
+	for _, d := range list {
+		if d.SDKUpgrade != nil {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.SDKUpgrade.Behind != b.SDKUpgrade.Behind {
+			return a.SDKUpgrade.Behind > b.SDKUpgrade.Behind
+		}
+		return a.Status.Rank() > b.Status.Rank()
+	})
+	return out
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangelogEntries(t *testing.T) {
+	cases := []struct {
+		name  string
+		notes string
+		want  []string
+	}{
+		{name: "empty", notes: "", want: nil},
+		{name: "paragraph only", notes: "Bug fix release.\n\nNo breaking changes.", want: nil},
+		{
+			name:  "markers",
+			notes: "- dash\n* star\n+ plus",
+			want:  []string{"dash", "star", "plus"},
+		},
+		{
+			name: "headings and paragraphs",
+			notes: "## Changes\n\n" +
+				"This release improves the positions.\n\n" +
+				"- fix the offsets of comments\n" +
+				"- add the `Pos` type  \n\n" +
+				"### Breaking\n\n" +
+				"* remove the `Token` field\n",
+			want: []string{"fix the offsets of comments", "add the `Pos` type", "remove the `Token` field"},
+		},
+		{
+			name:  "nested and CRLF",
+			notes: "- outer\r\n  - inner\r\n",
+			want:  []string{"outer", "inner"},
+		},
+		{
+			// emphasis and horizontal rules are not list items
+			name:  "not a list",
+			notes: "*emphasis*\n---\n-1 is negative",
+			want:  nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := changelogEntries(tc.notes)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
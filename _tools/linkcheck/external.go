@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	jobs      = flag.Int("j", 8, "number of external links to check in parallel")
+	timeout   = flag.Duration("timeout", 15*time.Second, "timeout for checking an external link")
+	cacheFile = flag.String("cache", "", "file to cache the results of external links checks in")
+	cacheTTL  = flag.Duration("cache-ttl", 24*time.Hour, "time to keep successful results in the cache")
+)
+
+// cache contains the time of the last successful check of external links.
+// Failed checks are not cached, thus they are retried on each run.
+type cache map[string]time.Time
+
+func loadCache(path string) cache {
+	c := make(cache)
+	if path == "" {
+		return c
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c
+	} else if err != nil {
+		log.Printf("warning: cannot read the cache: %v", err)
+		return c
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		log.Printf("warning: cannot decode the cache: %v", err)
+		return make(cache)
+	}
+	return c
+}
+
+func (c cache) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// checkExternal checks all external links and returns errors for the broken
+// ones, indexed by URL.
+func checkExternal(links map[string][]Link) map[string]error {
+	c := loadCache(*cacheFile)
+	now := time.Now()
+
+	cli := &http.Client{Timeout: *timeout}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		broken = make(map[string]error)
+		tokens = make(chan struct{}, *jobs)
+	)
+	for url := range links {
+		if strings.HasPrefix(url, "mailto:") {
+			continue
+		}
+		if t, ok := c[url]; ok && now.Sub(t) < *cacheTTL {
+			continue
+		}
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			tokens <- struct{}{}
+			defer func() {
+				<-tokens
+			}()
+
+			err := checkURL(cli, url)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				broken[url] = err
+				delete(c, url)
+			} else {
+				c[url] = now
+			}
+		}(url)
+	}
+	wg.Wait()
+
+	if err := c.save(*cacheFile); err != nil {
+		log.Printf("warning: cannot save the cache: %v", err)
+	}
+	return broken
+}
+
+// checkURL checks that the URL is reachable. Some servers do not support
+// HEAD requests, thus GET is used if HEAD fails.
+func checkURL(cli *http.Client, url string) error {
+	var last error
+	for _, method := range []string{"HEAD", "GET"} {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := cli.Do(req)
+		if err != nil {
+			last = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 400 {
+			return nil
+		}
+		last = fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return last
+}
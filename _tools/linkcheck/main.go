@@ -0,0 +1,235 @@
+// Command linkcheck checks links in the documentation pages.
+//
+// Relative links must point to existing files, and anchors must match one of
+// the headings of the target page. External links are checked only if
+// requested, since it requires network access.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	root     = flag.String("root", ".", "root directory of the documentation")
+	external = flag.Bool("external", false, "check external links as well")
+)
+
+var (
+	// inline links and images: [text](url "title")
+	inlineRe = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	// reference definitions: [id]: url
+	refRe = regexp.MustCompile(`^\s*\[[^\]]+\]:\s*<?(\S+?)>?(?:\s+.*)?$`)
+	// HTML links and anchors
+	hrefRe   = regexp.MustCompile(`<a\s[^>]*href="([^"]+)"`)
+	anchorRe = regexp.MustCompile(`<a\s[^>]*(?:name|id)="([^"]+)"`)
+	codeRe   = regexp.MustCompile("`[^`]*`")
+)
+
+// Link is a link found in a page.
+type Link struct {
+	File string
+	Line int
+	URL  string
+}
+
+func (l Link) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.File, l.Line, l.URL)
+}
+
+// Page contains the links and the anchors of a documentation page.
+type Page struct {
+	Links   []Link
+	Anchors map[string]bool
+}
+
+func main() {
+	flag.Parse()
+
+	pages, err := loadPages(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		broken []string
+		ext    = make(map[string][]Link)
+	)
+	for _, p := range pages {
+		for _, l := range p.Links {
+			if isExternal(l.URL) {
+				ext[l.URL] = append(ext[l.URL], l)
+				continue
+			}
+			if err := checkLocal(pages, l); err != nil {
+				broken = append(broken, fmt.Sprintf("%v: %v", l, err))
+			}
+		}
+	}
+	if *external {
+		for url, err := range checkExternal(ext) {
+			for _, l := range ext[url] {
+				broken = append(broken, fmt.Sprintf("%v: %v", l, err))
+			}
+		}
+	}
+	sort.Strings(broken)
+	for _, s := range broken {
+		fmt.Println(s)
+	}
+	if len(broken) != 0 {
+		os.Exit(1)
+	}
+}
+
+func isExternal(url string) bool {
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "mailto:")
+}
+
+// loadPages reads all Markdown pages in the documentation tree. Pages are
+// indexed by the path relative to the root.
+func loadPages(root string) (map[string]*Page, error) {
+	pages := make(map[string]*Page)
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := fi.Name()
+		if fi.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") ||
+				strings.HasPrefix(name, "_") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(name) != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		p, err := loadPage(path, rel)
+		if err != nil {
+			return err
+		}
+		pages[rel] = p
+		return nil
+	})
+	return pages, err
+}
+
+// loadPage extracts links and anchors from a page. Code blocks are skipped.
+func loadPage(path, rel string) (*Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &Page{Anchors: make(map[string]bool)}
+	var (
+		line  int
+		fence bool
+		// GitBook adds a suffix to the anchors of headings with the same text
+		seen = make(map[string]int)
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line++
+		s := sc.Text()
+		if strings.HasPrefix(strings.TrimSpace(s), "```") {
+			fence = !fence
+			continue
+		}
+		if fence {
+			continue
+		}
+		s = codeRe.ReplaceAllString(s, "")
+		if strings.HasPrefix(s, "#") {
+			a := slug(strings.TrimLeft(s, "# "))
+			if n := seen[a]; n != 0 {
+				p.Anchors[fmt.Sprintf("%s-%d", a, n)] = true
+			} else {
+				p.Anchors[a] = true
+			}
+			seen[a]++
+		}
+		for _, m := range anchorRe.FindAllStringSubmatch(s, -1) {
+			p.Anchors[m[1]] = true
+		}
+		var urls []string
+		for _, re := range []*regexp.Regexp{inlineRe, refRe, hrefRe} {
+			for _, m := range re.FindAllStringSubmatch(s, -1) {
+				urls = append(urls, m[1])
+			}
+		}
+		for _, u := range urls {
+			p.Links = append(p.Links, Link{File: rel, Line: line, URL: u})
+		}
+	}
+	return p, sc.Err()
+}
+
+// slug returns the anchor of a heading, the same way GitBook generates it.
+func slug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// checkLocal checks that a relative link points to an existing file, and that
+// the anchor exists if the target is a page.
+func checkLocal(pages map[string]*Page, l Link) error {
+	target, anchor := l.URL, ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, anchor = target[:i], target[i+1:]
+	}
+	if i := strings.Index(target, "?"); i >= 0 {
+		target = target[:i]
+	}
+	rel := l.File
+	if target != "" {
+		if strings.HasPrefix(target, "/") {
+			rel = filepath.Clean(filepath.FromSlash(target[1:]))
+		} else {
+			rel = filepath.Join(filepath.Dir(l.File), filepath.FromSlash(target))
+		}
+		if strings.HasSuffix(rel, ".html") {
+			// GitBook renders pages to HTML files with the same name
+			if md := strings.TrimSuffix(rel, ".html") + ".md"; pages[md] != nil {
+				rel = md
+			}
+		}
+		if _, err := os.Stat(filepath.Join(*root, rel)); err != nil {
+			return fmt.Errorf("file does not exist")
+		}
+	}
+	if anchor == "" {
+		return nil
+	}
+	p, ok := pages[rel]
+	if !ok {
+		// anchors are checked only for pages
+		return nil
+	}
+	if !p.Anchors[anchor] {
+		return fmt.Errorf("anchor does not exist")
+	}
+	return nil
+}
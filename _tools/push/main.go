@@ -0,0 +1,186 @@
+// Command push commits the regenerated reports to a branch and opens a pull
+// request with them, thus generated files are never left stale.
+//
+// Usage:
+//
+//	push [flags] <path>...
+//
+// Only the given paths are committed on top of the base branch, thus unrelated
+// changes and local commits are not included, and the checkout is left as is.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const githubAPI = "https://api.github.com"
+
+var (
+	repo   = flag.String("repo", "bblfsh/documentation", "GitHub repository to open the pull request in")
+	remote = flag.String("remote", "origin", "Git remote of the repository to push the branch to")
+	base   = flag.String("base", "master", "branch to open the pull request against")
+	branch = flag.String("branch", "auto/update-reports", "branch to commit the reports to")
+	labels = flag.String("labels", "automated", "comma-separated list of labels for the pull request")
+	title  = flag.String("title", "Update generated reports", "title of the commit and the pull request")
+)
+
+func main() {
+	flag.Parse()
+	paths := flag.Args()
+	if len(paths) == 0 {
+		log.Fatal("usage: push [flags] <path>...")
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		log.Fatal("GITHUB_TOKEN must be set")
+	}
+	stat, err := commit(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if stat == "" {
+		log.Println("reports are up to date")
+		return
+	}
+	if err := openPullRequest(token, stat); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// commit commits the given paths on top of the base branch of the remote and
+// force-pushes the commit to the branch, thus an open pull request always
+// contains the latest reports. A temporary index is used, thus the checkout,
+// including the current branch and the staged changes, is left as is. It
+// returns the diff stats, or an empty string if the reports are up to date.
+func commit(paths []string) (string, error) {
+	if _, err := git(nil, "fetch", "-q", *remote, *base); err != nil {
+		return "", err
+	}
+	parent, err := git(nil, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", err
+	}
+	parent = strings.TrimSpace(parent)
+
+	f, err := ioutil.TempFile("", "push-index")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	env := []string{"GIT_INDEX_FILE=" + f.Name()}
+
+	if _, err := git(env, "read-tree", parent); err != nil {
+		return "", err
+	}
+	if _, err := git(env, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	stat, err := git(env, "diff", "--cached", "--stat", parent)
+	if err != nil || stat == "" {
+		return "", err
+	}
+	tree, err := git(env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	rev, err := git(nil, "commit-tree", strings.TrimSpace(tree), "-p", parent, "-m", *title)
+	if err != nil {
+		return "", err
+	}
+	ref := strings.TrimSpace(rev) + ":refs/heads/" + *branch
+	if _, err := git(nil, "push", "-q", "-f", *remote, ref); err != nil {
+		return "", err
+	}
+	return stat, nil
+}
+
+// git runs the Git command with additional environment variables and returns
+// its output.
+func git(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+type pullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+}
+
+// openPullRequest opens a pull request for the branch, unless there is one
+// already, and sets its labels.
+func openPullRequest(token, stat string) error {
+	owner := strings.SplitN(*repo, "/", 2)[0]
+
+	var open []pullRequest
+	err := call(token, "GET", fmt.Sprintf("/repos/%s/pulls?state=open&head=%s:%s", *repo, owner, *branch), nil, &open)
+	if err != nil {
+		return err
+	}
+	if len(open) != 0 {
+		log.Println("pull request is updated:", open[0].URL)
+		return nil
+	}
+
+	var pr pullRequest
+	err = call(token, "POST", "/repos/"+*repo+"/pulls", map[string]string{
+		"title": *title,
+		"head":  *branch,
+		"base":  *base,
+		"body":  "Reports regenerated by `make push`.\n\n```\n" + stat + "```\n",
+	}, &pr)
+	if err != nil {
+		return err
+	}
+	log.Println("pull request is opened:", pr.URL)
+
+	if *labels == "" {
+		return nil
+	}
+	return call(token, "POST", fmt.Sprintf("/repos/%s/issues/%d/labels", *repo, pr.Number), map[string][]string{
+		"labels": strings.Split(*labels, ","),
+	}, nil)
+}
+
+// call makes a GitHub API request and decodes the response to out, if set.
+func call(token, method, path string, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, githubAPI+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: unexpected status: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
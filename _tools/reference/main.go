@@ -0,0 +1,297 @@
+// Command reference generates the reference of the UAST types from the SDK
+// source, thus the fields, their types and optionality always match the code.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// UASTPackage is the import path of the SDK package with the UAST types.
+const UASTPackage = "gopkg.in/bblfsh/sdk.v1/uast"
+
+// RoleType is the name of the type of UAST roles. The roles themselves are
+// described by the roles list.
+const RoleType = "Role"
+
+var (
+	sdkDir = flag.String("sdk", "", "load the SDK from the source checkout in this directory instead of GOPATH")
+)
+
+const header = "" +
+	"<!-- Code generated by 'make reference' DO NOT EDIT. -->\n\n" +
+	"# UAST reference\n\n" +
+	"Types of the UAST package of the SDK with their fields, as defined in the code. " +
+	"See the [specification](specification.md) for the description of the UAST structure.\n"
+
+// Type is an exported type of the UAST package.
+type Type struct {
+	Name string
+	Doc  string
+	// Underlying is the underlying type of non-struct types.
+	Underlying string
+	// Fields are the exported fields of struct types.
+	Fields []Field
+	// Consts is the number of exported constants of the type.
+	Consts int
+}
+
+// Field is an exported field of a struct type.
+type Field struct {
+	Name string
+	// Key is the name of the field in the JSON encoding.
+	Key  string
+	Type string
+	Doc  string
+	// Optional is set for fields that may be omitted in the encoding or
+	// have no value, i.e. pointers, slices and maps.
+	Optional bool
+	// Embedded is set for embedded fields, the fields of which are
+	// promoted to the struct.
+	Embedded bool
+}
+
+func main() {
+	flag.Parse()
+
+	pkg, files, err := loadPackage()
+	if err != nil {
+		log.Fatal(err)
+	}
+	list := packageTypes(pkg, files)
+	buf := bytes.NewBufferString(header)
+	writeTypes(buf, list)
+	os.Stdout.Write(buf.Bytes())
+}
+
+// loadPackage loads and type checks the UAST package of the SDK.
+func loadPackage() (*types.Package, []*ast.File, error) {
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	if *sdkDir != "" {
+		dir := filepath.Join(*sdkDir, "uast")
+		p, err := build.ImportDir(dir, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		var files []string
+		for _, name := range p.GoFiles {
+			files = append(files, filepath.Join(dir, name))
+		}
+		conf.CreateFromFilenames(UASTPackage, files...)
+	} else {
+		conf.Import(UASTPackage)
+	}
+	prog, err := conf.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	var info *loader.PackageInfo
+	if *sdkDir != "" {
+		info = prog.Created[0]
+	} else {
+		info = prog.Package(UASTPackage)
+	}
+	return info.Pkg, info.Files, nil
+}
+
+// typeSpecs returns the declarations of the types in the files with the doc
+// comments, by the type name.
+func typeSpecs(files []*ast.File) map[string]*ast.TypeSpec {
+	specs := make(map[string]*ast.TypeSpec)
+	for _, f := range files {
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, s := range decl.Specs {
+				spec, ok := s.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				// the comment of a single type is attached to the declaration
+				if spec.Doc == nil && len(decl.Specs) == 1 {
+					spec.Doc = decl.Doc
+				}
+				specs[spec.Name.Name] = spec
+			}
+		}
+	}
+	return specs
+}
+
+// fieldDocs returns the doc comments of the struct fields by the field name.
+func fieldDocs(spec *ast.TypeSpec) map[string]string {
+	docs := make(map[string]string)
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return docs
+	}
+	for _, f := range st.Fields.List {
+		text := f.Doc.Text()
+		if text == "" {
+			text = f.Comment.Text()
+		}
+		for _, id := range f.Names {
+			docs[id.Name] = text
+		}
+		if len(f.Names) == 0 {
+			// embedded field, named after the type
+			docs[types.ExprString(f.Type)] = text
+		}
+	}
+	return docs
+}
+
+// packageTypes returns the exported types of the package sorted by name.
+func packageTypes(pkg *types.Package, files []*ast.File) []Type {
+	specs := typeSpecs(files)
+	qual := types.RelativeTo(pkg)
+
+	consts := make(map[types.Type]int)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && c.Exported() {
+			consts[c.Type()]++
+		}
+	}
+
+	var list []Type
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !tn.Exported() {
+			continue
+		}
+		t := Type{Name: name, Consts: consts[tn.Type()]}
+		var docs map[string]string
+		if spec := specs[name]; spec != nil {
+			t.Doc = spec.Doc.Text()
+			docs = fieldDocs(spec)
+		}
+		st, ok := tn.Type().Underlying().(*types.Struct)
+		if !ok {
+			t.Underlying = types.TypeString(tn.Type().Underlying(), qual)
+			list = append(list, t)
+			continue
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			key, omitEmpty := jsonKey(f.Name(), st.Tag(i))
+			if key == "-" {
+				continue
+			}
+			typ := types.TypeString(f.Type(), qual)
+			t.Fields = append(t.Fields, Field{
+				Name:     f.Name(),
+				Key:      key,
+				Type:     typ,
+				Doc:      docs[f.Name()],
+				Optional: omitEmpty || isNillable(f.Type()),
+				Embedded: f.Anonymous(),
+			})
+		}
+		list = append(list, t)
+	}
+	return list
+}
+
+// jsonKey returns the name of the field in the JSON encoding, and if it's
+// omitted when empty.
+func jsonKey(name, tag string) (string, bool) {
+	v := reflect.StructTag(tag).Get("json")
+	parts := strings.Split(v, ",")
+	key := parts[0]
+	if key == "" {
+		key = name
+	}
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return key, omitEmpty
+}
+
+// isNillable checks if the value of the type may be nil.
+func isNillable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface:
+		return true
+	}
+	return false
+}
+
+// typeLink returns the type formatted as code, with a link to its reference
+// if it refers to one of the listed types.
+func typeLink(typ string, known map[string]bool) string {
+	base := typ
+	for _, prefix := range []string{"*", "[]", "map[string]"} {
+		for strings.HasPrefix(base, prefix) {
+			base = strings.TrimPrefix(base, prefix)
+		}
+	}
+	if known[base] {
+		return fmt.Sprintf("[`%s`](#%s)", typ, strings.ToLower(base))
+	}
+	return "`" + typ + "`"
+}
+
+func writeTypes(buf *bytes.Buffer, list []Type) {
+	known := make(map[string]bool, len(list))
+	for _, t := range list {
+		known[t.Name] = true
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	for _, t := range list {
+		fmt.Fprintf(buf, "\n## %s\n\n", t.Name)
+		if doc := strings.TrimSpace(t.Doc); doc != "" {
+			buf.WriteString(doc + "\n\n")
+		}
+		if t.Underlying != "" {
+			fmt.Fprintf(buf, "Underlying type: %s.\n", typeLink(t.Underlying, known))
+		}
+		if t.Consts != 0 {
+			if t.Name == RoleType {
+				fmt.Fprintf(buf, "\nThere are %d roles, see the [roles list](roles.md) for their descriptions.\n", t.Consts)
+			} else {
+				fmt.Fprintf(buf, "\nThere are %d constants of this type.\n", t.Consts)
+			}
+		}
+		if len(t.Fields) == 0 {
+			continue
+		}
+		buf.WriteString("Field|JSON|Type|Optional|Description\n-|-|-|-|-\n")
+		for _, f := range t.Fields {
+			desc := doc.Synopsis(f.Doc)
+			if f.Embedded {
+				desc = strings.TrimSpace("Embedded, its fields are promoted. " + desc)
+			}
+			opt := ""
+			if f.Optional {
+				opt = "✓"
+			}
+			fmt.Fprintf(buf, "%s|`%s`|%s|%s|%s\n", f.Name, f.Key, typeLink(f.Type, known), opt,
+				strings.Replace(desc, "|", `\|`, -1))
+		}
+	}
+}
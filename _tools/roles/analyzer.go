@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Analyzer is a report that analyzes each driver independently of the roles
+// usage. New reports are added by registering an analyzer, see register.
+type Analyzer interface {
+	// Name is the name of the report, as selected with -report.
+	Name() string
+	// Run analyzes the driver and merges the result with the results for
+	// other drivers.
+	Run(language, pkg string) error
+	// Render renders the report for all analyzed drivers.
+	Render() string
+}
+
+var analyzers = make(map[string]Analyzer)
+
+// register makes the analyzer available with -report. It's usually called
+// from the init function of the file implementing the report.
+func register(a Analyzer) {
+	name := a.Name()
+	if registered(name) {
+		panic(fmt.Errorf("analyzer %q is registered twice", name))
+	}
+	analyzers[name] = a
+}
+
+// registered checks if there is an analyzer of either kind with a given name.
+func registered(name string) bool {
+	_, ok := analyzers[name]
+	_, rok := rolesAnalyzers[name]
+	return ok || rok
+}
+
+// driverAnalyzer implements Analyzer by collecting the results of a function
+// for each driver into a map by language, and rendering all of them at once.
+type driverAnalyzer[T any] struct {
+	name    string
+	find    func(language, pkg string) (T, error)
+	report  func(map[string]T) string
+	results map[string]T
+}
+
+// newAnalyzer creates an analyzer from a function that analyzes a single
+// driver and a function that renders the results for all of them.
+func newAnalyzer[T any](name string, find func(language, pkg string) (T, error), report func(map[string]T) string) Analyzer {
+	return &driverAnalyzer[T]{
+		name: name, find: find, report: report,
+		results: make(map[string]T),
+	}
+}
+
+// byPackage adapts a function that doesn't need the language of the driver.
+func byPackage[T any](fn func(pkg string) (T, error)) func(language, pkg string) (T, error) {
+	return func(_, pkg string) (T, error) {
+		return fn(pkg)
+	}
+}
+
+func (a *driverAnalyzer[T]) Name() string {
+	return a.name
+}
+
+func (a *driverAnalyzer[T]) Run(language, pkg string) error {
+	res, err := a.find(language, pkg)
+	if err != nil {
+		return err
+	}
+	a.results[language] = res
+	return nil
+}
+
+func (a *driverAnalyzer[T]) Render() string {
+	return a.report(a.results)
+}
+
+// RolesAnalyzer is a report that analyzes each driver after the roles used by
+// the annotations of all drivers are found, e.g. to find which of them are
+// used by the fixtures. New reports are added by registering an analyzer, see
+// registerRoles.
+type RolesAnalyzer interface {
+	// Name is the name of the report, as selected with -report.
+	Name() string
+	// Run analyzes the driver and merges the result with the results for
+	// other drivers.
+	Run(language, pkg string, roles Roles) error
+	// Render writes the report for all analyzed drivers.
+	Render(w io.Writer, roles Roles) error
+}
+
+var rolesAnalyzers = make(map[string]RolesAnalyzer)
+
+// registerRoles makes the analyzer available with -report. It's usually
+// called from the init function of the file implementing the report.
+func registerRoles(a RolesAnalyzer) {
+	name := a.Name()
+	if registered(name) {
+		panic(fmt.Errorf("analyzer %q is registered twice", name))
+	}
+	rolesAnalyzers[name] = a
+}
+
+// rolesAnalyzer implements RolesAnalyzer by collecting the results of
+// a function for each driver into a map by language, and rendering all of
+// them at once.
+type rolesAnalyzer[T any] struct {
+	name    string
+	find    func(language, pkg string, roles Roles) (T, error)
+	report  func(w io.Writer, roles Roles, results map[string]T) error
+	results map[string]T
+}
+
+// newRolesAnalyzer creates an analyzer from a function that analyzes a single
+// driver and a function that writes the report for all of them. The find
+// function may be nil for reports that only need the roles usage.
+func newRolesAnalyzer[T any](name string, find func(language, pkg string, roles Roles) (T, error), report func(w io.Writer, roles Roles, results map[string]T) error) RolesAnalyzer {
+	return &rolesAnalyzer[T]{
+		name: name, find: find, report: report,
+		results: make(map[string]T),
+	}
+}
+
+// withoutRoles adapts a function that doesn't need the roles usage.
+func withoutRoles[T any](fn func(language, pkg string) (T, error)) func(language, pkg string, roles Roles) (T, error) {
+	return func(language, pkg string, _ Roles) (T, error) {
+		return fn(language, pkg)
+	}
+}
+
+func (a *rolesAnalyzer[T]) Name() string {
+	return a.name
+}
+
+func (a *rolesAnalyzer[T]) Run(language, pkg string, roles Roles) error {
+	if a.find == nil {
+		return nil
+	}
+	res, err := a.find(language, pkg, roles)
+	if err != nil {
+		return err
+	}
+	a.results[language] = res
+	return nil
+}
+
+func (a *rolesAnalyzer[T]) Render(w io.Writer, roles Roles) error {
+	return a.report(w, roles, a.results)
+}
+
+// printReport writes the report rendered by fn, followed by a newline.
+func printReport(w io.Writer, fn func() string) error {
+	_, err := fmt.Fprintln(w, render(fn))
+	return err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/token"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("app", appExamples, func(w io.Writer, roles Roles, examples map[string]map[string]*Example) error {
+		stop := timings.track(PhaseRender, "")
+		html, err := roles.App(examples)
+		stop()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, html)
+		return err
+	}))
+}
+
+// appExamples finds the roles used by the fixtures of the driver, shown in the
+// app along with the examples, and returns the examples.
+func appExamples(language, pkg string, roles Roles) (map[string]*Example, error) {
+	if _, err := fixtureUsage(language, pkg, roles); err != nil {
+		return nil, err
+	}
+	return findExamples(language, pkg)
+}
+
+//go:embed app/*.tmpl
+var appFiles embed.FS
+
+var appTemplate = template.Must(template.ParseFS(appFiles, "app/index.html.tmpl"))
+
+// appData is embedded into the HTML app as JSON.
+type appData struct {
+	Languages []string  `json:"languages"`
+	Roles     []appRole `json:"roles"`
+}
+
+type appRole struct {
+	Name        string                 `json:"name"`
+	Doc         string                 `json:"doc"`
+	Annotations map[string]int         `json:"annotations,omitempty"`
+	Fixtures    map[string]int         `json:"fixtures,omitempty"`
+	Examples    map[string]*appExample `json:"examples,omitempty"`
+}
+
+type appExample struct {
+	Source string `json:"source"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Link   string `json:"link"`
+}
+
+// App renders a self-contained HTML page with the roles usage of each driver,
+// the search by the role name or description, and the examples of each role.
+// The data is embedded into the page, thus it can be published as is.
+func (r Roles) App(examples map[string]map[string]*Example) (string, error) {
+	data := appData{Languages: languages()}
+	for _, rc := range r.Coverage().Roles {
+		data.Roles = append(data.Roles, appRole{
+			Name:        rc.Name,
+			Annotations: rc.Languages,
+			Fixtures:    rc.Fixtures,
+		})
+	}
+	for i, role := range r {
+		ar := &data.Roles[i]
+		ar.Doc = strings.TrimSpace(role.Doc)
+		for lang, m := range examples {
+			ex := m[role.Name]
+			if ex == nil {
+				continue
+			}
+			if ar.Examples == nil {
+				ar.Examples = make(map[string]*appExample)
+			}
+			pos := token.Position{Filename: ex.Path, Line: ex.Line}
+			ar.Examples[lang] = &appExample{
+				Source: ex.Source,
+				File:   filepath.Base(ex.Path),
+				Line:   ex.Line,
+				Link:   githubLink(lang, fixturesDir(OfficialDriver[lang]), pos),
+			}
+		}
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := appTemplate.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	archiveDir  = flag.String("archive", "", "subdirectory of the directory of the roles report to save a dated copy of the report and its JSON form to, for example uast/history")
+	archiveKeep = flag.Int("archive-keep", 0, "number of the latest dated reports to keep in the archive (all if zero)")
+)
+
+// archiveNameRe matches the names of the dated reports in the archive.
+var archiveNameRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.(md|json)$`)
+
+// linkRe matches the targets of Markdown links.
+var linkRe = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+
+// rebaseLinks prefixes the relative link targets in the Markdown document,
+// e.g. with "../" for a copy of the document in a subdirectory. Absolute
+// URLs and links within the document are left as is.
+func rebaseLinks(md, prefix string) string {
+	return linkRe.ReplaceAllStringFunc(md, func(link string) string {
+		target := link[2 : len(link)-1]
+		if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") ||
+			strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+			return link
+		}
+		return "](" + prefix + target + ")"
+	})
+}
+
+// archiveReport writes the roles report to <dir>/YYYY-MM-DD.md along with
+// the coverage to <dir>/YYYY-MM-DD.json, that is used by the changelog.
+// Reports of the same day are overwritten. Fixtures are analyzed on a copy
+// of the roles, thus the main report is not affected. The archive is expected
+// to be a subdirectory of the directory of the report, thus relative links are
+// rebased to the parent directory.
+func archiveReport(dir string, roles Roles, md string, now time.Time) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	date := now.Format("2006-01-02")
+
+	// the archived page should not have the same title as the main one
+	md = strings.Replace(md, "# Roles list\n", "# Roles list on "+date+"\n", 1)
+	md = rebaseLinks(md, "../")
+	if err := ioutil.WriteFile(filepath.Join(dir, date+".md"), []byte(md), 0644); err != nil {
+		return err
+	}
+
+	fixtures := roles.clean()
+	unknown := make(UnknownRoles)
+	for l, pkg := range OfficialDriver {
+		if err := findFixtureUsage(l, pkg, fixtures, unknown); err != nil {
+			return err
+		}
+	}
+	c := roles.Coverage()
+	// both lists are in the same order, since one is a copy of the other
+	for i, rc := range fixtures.Coverage().Roles {
+		c.Roles[i].Fixtures = rc.Fixtures
+	}
+	f, err := os.Create(filepath.Join(dir, date+".json"))
+	if err != nil {
+		return err
+	}
+	if err := writeCoverage(f, c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// pruneArchive removes the oldest dated reports from the archive, so only
+// the given number of the latest ones is kept.
+func pruneArchive(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	byDate := make(map[string][]string)
+	var dates []string
+	for _, fi := range files {
+		m := archiveNameRe.FindStringSubmatch(fi.Name())
+		if m == nil || fi.IsDir() {
+			continue
+		}
+		if _, ok := byDate[m[1]]; !ok {
+			dates = append(dates, m[1])
+		}
+		byDate[m[1]] = append(byDate[m[1]], fi.Name())
+	}
+	if len(dates) <= keep {
+		return nil
+	}
+	// dates in this format are ordered as strings
+	sort.Strings(dates)
+	for _, date := range dates[:len(dates)-keep] {
+		for _, name := range byDate[date] {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			log.Printf("archive: removed %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("bench", byPackage(findBench), BenchReport))
+}
+
+// BenchFile is the file in the driver repository with committed results of
+// the driver benchmarks, in the format of 'go test -bench' output.
+const BenchFile = "benchmarks.txt"
+
+var (
+	runBench = flag.Bool("run-bench", false, "run driver benchmarks instead of reading committed "+BenchFile+" files")
+)
+
+// Benchmark is a single result of a Go benchmark.
+type Benchmark struct {
+	Name    string
+	NsPerOp float64
+}
+
+// BenchStats contains benchmark results of a driver and the size of the
+// fixtures the benchmarks parse.
+type BenchStats struct {
+	Benchmarks  []Benchmark
+	SourceLines int
+}
+
+// Total returns the time of a single run of all benchmarks in nanoseconds.
+func (st *BenchStats) Total() float64 {
+	var sum float64
+	for _, b := range st.Benchmarks {
+		sum += b.NsPerOp
+	}
+	return sum
+}
+
+// PerKLOC returns the time in milliseconds the driver takes to parse
+// a thousand lines of fixtures sources.
+func (st *BenchStats) PerKLOC() float64 {
+	if st.SourceLines == 0 {
+		return 0
+	}
+	return st.Total() / 1e6 / (float64(st.SourceLines) / 1000)
+}
+
+// benchLineRe matches a result line of 'go test -bench', e.g.
+// "BenchmarkParse-8   100   12345 ns/op".
+var benchLineRe = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+([0-9.]+) ns/op`)
+
+// parseBench reads benchmark results in the format of 'go test -bench' output.
+// Other lines are ignored.
+func parseBench(r io.Reader) ([]Benchmark, error) {
+	var list []Benchmark
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		m := benchLineRe.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		ns, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Benchmark{Name: m[1], NsPerOp: ns})
+	}
+	return list, sc.Err()
+}
+
+// findBench collects the benchmark results of a driver. Results are read from
+// the committed file, or the benchmarks are run if requested. Drivers without
+// results are skipped by returning nil.
+func findBench(pkg string) (*BenchStats, error) {
+	dir, err := driverDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	if *runBench {
+		cmd := exec.Command("go", "test", "-run=^$", "-bench=.", "./...")
+		cmd.Dir = dir
+		cmd.Stderr = os.Stderr
+		out, err = cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go test -bench: %v", err)
+		}
+	} else {
+		out, err = ioutil.ReadFile(filepath.Join(dir, BenchFile))
+		if os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	list, err := parseBench(bytes.NewReader(out))
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+
+	st := &BenchStats{Benchmarks: list}
+	sources, err := findFixtures(pkg, ".source")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range sources {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		st.SourceLines += bytes.Count(data, []byte("\n"))
+	}
+	return st, nil
+}
+
+const benchHeader = "" +
+	"# Parsing performance\n\n" +
+	"The table compares the results of the driver benchmarks. Drivers benchmark " +
+	"parsing of their fixtures, thus the time is normalized by the number of lines " +
+	"in fixtures sources (`*.source` files). Results are only comparable if the " +
+	"benchmarks were run on the same machine.\n\n"
+
+// BenchReport renders the parsing performance of each language, followed by
+// the results of individual benchmarks.
+func BenchReport(stats map[string]*BenchStats) string {
+	buf := bytes.NewBuffer([]byte(benchHeader))
+	buf.WriteString("Language|Benchmarks|Source lines|Total, ms|ms per KLOC\n")
+	buf.WriteString("-|-|-|-|-\n")
+	for _, lang := range languages() {
+		st := stats[lang]
+		if st == nil {
+			continue
+		}
+		fmt.Fprintf(buf, "%s|%d|%d|%.2f|%.2f\n", strings.Title(lang),
+			len(st.Benchmarks), st.SourceLines, st.Total()/1e6, st.PerKLOC())
+	}
+
+	for _, lang := range languages() {
+		st := stats[lang]
+		if st == nil {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		buf.WriteString("Benchmark|ns/op\n-|-\n")
+		for _, b := range st.Benchmarks {
+			fmt.Fprintf(buf, "%s|%.0f\n", b.Name, b.NsPerOp)
+		}
+	}
+	return buf.String()
+}
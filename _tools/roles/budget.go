@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"sync"
+)
+
+var (
+	maxMem = flag.Int64("max-mem", 0, "limit of the total size of fixture files decoded at the same time, in MB (0 is unlimited)")
+)
+
+// memBudget limits the total size of the files processed concurrently.
+type memBudget struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	max  int64
+	used int64
+}
+
+func newMemBudget(max int64) *memBudget {
+	b := &memBudget{max: max}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+var (
+	budgetOnce sync.Once
+	budget     *memBudget
+)
+
+// fixturesBudget returns the budget shared by all drivers, or nil if the
+// memory usage is not limited.
+func fixturesBudget() *memBudget {
+	budgetOnce.Do(func() {
+		if *maxMem > 0 {
+			budget = newMemBudget(*maxMem << 20)
+		}
+	})
+	return budget
+}
+
+// acquire blocks until there is enough budget for a file of a given size.
+// Files larger than the whole budget are allowed if nothing else is in use,
+// otherwise they would never be processed. It returns false if the done
+// channel is closed while waiting; wake must be called after closing it.
+func (b *memBudget) acquire(size int64, done <-chan struct{}) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.used > 0 && b.used+size > b.max {
+		select {
+		case <-done:
+			return false
+		default:
+		}
+		b.cond.Wait()
+	}
+	b.used += size
+	return true
+}
+
+// wake unblocks all waiting calls to acquire to let them check if they
+// were canceled.
+func (b *memBudget) wake() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+// release returns the size of the processed file back to the budget.
+func (b *memBudget) release(size int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.used -= size
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
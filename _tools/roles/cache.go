@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// cacheVersion must be changed each time the Node structure or the decoder
+// changes, to invalidate the existing cache entries.
+const cacheVersion = "1"
+
+var (
+	cacheDir = flag.String("cache", "", "directory to cache decoded fixtures in (disabled if empty)")
+)
+
+// loadFixture decodes a fixture file. If the cache is enabled, decoded trees
+// are stored in it keyed by the hash of the file content, thus only fixtures
+// that actually changed are decoded again.
+func loadFixture(f *os.File) (*Node, error) {
+	if *cacheDir == "" {
+		return ParseFixture(f)
+	}
+
+	h := sha256.New()
+	io.WriteString(h, cacheVersion)
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(*cacheDir, hex.EncodeToString(h.Sum(nil)))
+
+	if n, err := readCached(path); err == nil {
+		return n, nil
+	}
+
+	n, err := ParseFixture(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCached(path, n); err != nil {
+		// cache is an optimization; the tree is still valid
+		log.Printf("warning: cannot cache %s: %v", f.Name(), err)
+	}
+	return n, nil
+}
+
+func readCached(path string) (*Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var n Node
+	if err := gob.NewDecoder(f).Decode(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func writeCached(path string, n *Node) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first, so concurrent or interrupted
+	// runs never observe a partial entry
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := gob.NewEncoder(f).Encode(n); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Category is a group of related roles.
+type Category struct {
+	Name  string
+	Roles []string
+}
+
+// OtherCategory is the name of the category for roles not listed in Categories.
+const OtherCategory = "Other"
+
+// Categories is the list of role categories in the order they are rendered.
+var Categories = []Category{
+	{Name: "Identifiers", Roles: []string{
+		"Identifier", "Qualified", "Name", "This", "Receiver",
+	}},
+	{Name: "Imports", Roles: []string{
+		"Import", "Pathname", "Alias",
+	}},
+	{Name: "Declarations", Roles: []string{
+		"Declaration", "Function", "Type", "Package", "Module", "File",
+		"Subpackage", "Visibility", "Instance", "Subtype", "Implements",
+		"Friend", "Base", "Scope", "Body",
+	}},
+	{Name: "Calls", Roles: []string{
+		"Call", "Callee", "Argument", "ArgsList", "Positional",
+	}},
+	{Name: "Operators", Roles: []string{
+		"Expression", "Operator", "Binary", "Unary", "Infix", "Postfix",
+		"Left", "Right", "Assignment", "Add", "Substract", "Multiply",
+		"Divide", "Modulo", "Bitwise", "And", "Or", "Xor", "Not", "Equal",
+		"Identical", "LessThan", "LessThanOrEqual", "GreaterThan",
+		"GreaterThanOrEqual", "LeftShift", "RightShift", "Contains",
+		"Negative", "Positive", "Increment", "Decrement", "Dereference",
+		"TakeAddress",
+	}},
+	{Name: "Literals", Roles: []string{
+		"Literal", "Primitive", "String", "Number", "Boolean", "Null",
+		"Character", "Byte", "ByteString", "Regexp", "Unsigned", "List",
+		"Map", "Set", "Tuple", "Key", "Value",
+	}},
+	{Name: "Statements", Roles: []string{
+		"Statement", "Block", "If", "Condition", "Then", "Else", "Switch",
+		"Case", "Default", "For", "While", "DoWhile", "Initialization",
+		"Update", "Iterator", "Break", "Continue", "Goto", "Return", "Try",
+		"Catch", "Finally", "Throw", "Assert", "Entry", "Noop",
+	}},
+	{Name: "Comments", Roles: []string{
+		"Comment", "Documentation", "Whitespace",
+	}},
+}
+
+// roleGroup is a list of roles of one category.
+type roleGroup struct {
+	Name  string
+	Roles Roles
+}
+
+// grouped splits the roles into categories, preserving the order of roles.
+func (r Roles) grouped() []roleGroup {
+	index := make(map[string]int)
+	for i, c := range Categories {
+		for _, name := range c.Roles {
+			index[name] = i
+		}
+	}
+	groups := make([]roleGroup, len(Categories)+1)
+	for i, c := range Categories {
+		groups[i].Name = c.Name
+	}
+	other := len(Categories)
+	groups[other].Name = OtherCategory
+	for _, role := range r {
+		i, ok := index[role.Name]
+		if !ok {
+			i = other
+		}
+		groups[i].Roles = append(groups[i].Roles, role)
+	}
+
+	out := groups[:0]
+	for _, g := range groups {
+		if len(g.Roles) != 0 {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// writeGroupedTableBody writes the roles table with a section header and
+// a subtotal row for each category.
+func writeGroupedTableBody(w *bytes.Buffer, r Roles, langs []string) {
+	for _, g := range r.grouped() {
+		fmt.Fprintf(w, "**%s**%s\n", g.Name, strings.Repeat("|", len(langs)))
+		writeTableRows(w, g.Roles, langs)
+
+		w.WriteString("*Subtotal*")
+		for _, lang := range langs {
+			n := 0
+			for _, role := range g.Roles {
+				if role.IsUsedBy(lang) {
+					n++
+				}
+			}
+			fmt.Fprintf(w, "|*%d/%d*", n, len(g.Roles))
+		}
+		w.WriteString("\n")
+	}
+
+	fmt.Fprintf(w, "\n\n")
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	feedFile = flag.String("feed", "", "file to write the Atom feed of the changelog to")
+	feedURL  = flag.String("feed-url", "https://doc.bblf.sh/uast/CHANGELOG-types.html", "URL of the changelog page the feed entries link to")
+)
+
+// ChangelogEntry is the difference between two consecutive coverage reports.
+type ChangelogEntry struct {
+	Date    time.Time
+	Changes []UsageChange
+	// Added and Removed are the languages added to or removed from the report.
+	Added, Removed []string
+}
+
+// reportDate returns the date of a saved coverage report. Reports archived by
+// date are named YYYY-MM-DD.json; the modification time is used for others.
+func reportDate(path string) (time.Time, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if t, err := time.Parse("2006-01-02", name); err == nil {
+		return t, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime().UTC(), nil
+}
+
+// loadChangelog reads the saved coverage reports and returns the changes
+// between each consecutive pair, newest first. Reports are ordered by date,
+// and entries without any changes are skipped.
+func loadChangelog(paths []string) ([]ChangelogEntry, error) {
+	type report struct {
+		date time.Time
+		c    *Coverage
+	}
+	var list []report
+	for _, path := range paths {
+		date, err := reportDate(path)
+		if err != nil {
+			return nil, err
+		}
+		c, err := loadCoverage(path)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, report{date: date, c: c})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].date.Before(list[j].date)
+	})
+
+	var out []ChangelogEntry
+	for i := len(list) - 1; i > 0; i-- {
+		a, b := list[i-1].c, list[i].c
+		e := ChangelogEntry{
+			Date:    list[i].date,
+			Changes: diffCoverage(a, b),
+			Added:   notIn(b.Languages, a.Languages),
+			Removed: notIn(a.Languages, b.Languages),
+		}
+		if len(e.Changes) == 0 && len(e.Added) == 0 && len(e.Removed) == 0 {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// notIn returns the names from a that are not in b.
+func notIn(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// runChangelog renders the changelog of the saved coverage reports and writes
+// the Atom feed of it, if requested.
+func runChangelog(w io.Writer, paths []string) error {
+	if len(paths) < 2 {
+		return fmt.Errorf("usage: changelog report.json report.json...")
+	}
+	entries, err := loadChangelog(paths)
+	if err != nil {
+		return err
+	}
+	if *feedFile != "" {
+		f, err := os.Create(*feedFile)
+		if err != nil {
+			return err
+		}
+		if err := writeFeed(f, entries, time.Now()); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, ChangelogReport(entries))
+	return err
+}
+
+const changelogHeader = "" +
+	"# Roles coverage changelog\n\n" +
+	"Changes of the roles usage by each driver between consecutive runs of the " +
+	"roles report, newest first. The changelog is also available as an " +
+	"[Atom feed](changelog.atom).\n"
+
+// ChangelogReport renders the changelog entries grouped by language.
+func ChangelogReport(entries []ChangelogEntry) string {
+	buf := bytes.NewBufferString(changelogHeader)
+	if len(entries) == 0 {
+		buf.WriteString("\nNo changes.\n")
+	}
+	for _, e := range entries {
+		fmt.Fprintf(buf, "\n## %s\n\n", e.Date.Format("2006-01-02"))
+		writeChangelogEntry(buf, e)
+	}
+	return buf.String()
+}
+
+func writeChangelogEntry(buf *bytes.Buffer, e ChangelogEntry) {
+	for _, lang := range e.Added {
+		fmt.Fprintf(buf, "- %s driver added\n", strings.Title(lang))
+	}
+	for _, lang := range e.Removed {
+		fmt.Fprintf(buf, "- %s driver removed\n", strings.Title(lang))
+	}
+	byLang := make(map[string][]UsageChange)
+	var langs []string
+	for _, c := range e.Changes {
+		if _, ok := byLang[c.Language]; !ok {
+			langs = append(langs, c.Language)
+		}
+		byLang[c.Language] = append(byLang[c.Language], c)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		fmt.Fprintf(buf, "- %s:\n", strings.Title(lang))
+		for _, c := range byLang[lang] {
+			fmt.Fprintf(buf, "  - `%s` in %s: %s (%d → %d)\n", c.Role, c.Source, c.Change(), c.Old, c.New)
+		}
+	}
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// writeFeed writes the changelog entries as an Atom feed. Entries link to the
+// changelog page, and their content is the same Markdown list as on the page.
+func writeFeed(w io.Writer, entries []ChangelogEntry, now time.Time) error {
+	feed := atomFeed{
+		Title:   "Babelfish roles coverage changelog",
+		ID:      *feedURL,
+		Link:    atomLink{Href: *feedURL},
+		Updated: now.UTC().Format(time.RFC3339),
+	}
+	if len(entries) != 0 {
+		feed.Updated = entries[0].Date.UTC().Format(time.RFC3339)
+	}
+	for _, e := range entries {
+		date := e.Date.Format("2006-01-02")
+		buf := new(bytes.Buffer)
+		writeChangelogEntry(buf, e)
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   fmt.Sprintf("Roles coverage changes on %s", date),
+			ID:      *feedURL + "#" + date,
+			Link:    atomLink{Href: *feedURL + "#" + date},
+			Updated: e.Date.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "text", Body: buf.String()},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
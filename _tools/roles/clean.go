@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runClean removes the leftovers from the repositories directory: clones of
+// the repositories that are not specified with -repo, corrupted clones and
+// build artifacts in the worktrees. Only the bare clones made by the tool and
+// their worktrees are removed, other directories are left as is.
+func runClean(repos []string) error {
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories are specified with -repo, refusing to remove all the clones")
+	}
+	root := reposRoot(*reposDir)
+	keep := make(map[string]bool)
+	for _, s := range repos {
+		url, _ := splitRepo(s)
+		bare, dir := root.bare(url), root.dir(url)
+		if _, err := os.Stat(bare); err == nil && !isValidClone(bare) {
+			log.Printf("removing corrupted clone %s", bare)
+			if err := removeClone(bare, dir); err != nil {
+				return err
+			}
+			continue
+		}
+		keep[bare], keep[dir] = true, true
+		if _, err := os.Stat(dir); err == nil {
+			// untracked and ignored files are build artifacts
+			if err := git(*fetchTimeout, dir, "clean", "--quiet", "-ffdx"); err != nil {
+				return err
+			}
+		}
+	}
+
+	owners, err := ioutil.ReadDir(string(root))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		odir := filepath.Join(string(root), owner.Name())
+		if !owner.IsDir() {
+			continue
+		}
+		list, err := ioutil.ReadDir(odir)
+		if err != nil {
+			return err
+		}
+		left := len(list)
+		for _, fi := range list {
+			path := filepath.Join(odir, fi.Name())
+			if keep[path] {
+				continue
+			} else if !isOwnClone(path) {
+				// the directory may be shared with other checkouts
+				log.Printf("warning: skipping %s, it's not a clone made by this tool", path)
+				continue
+			}
+			log.Printf("removing stale clone %s", path)
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			left--
+		}
+		if left == 0 {
+			if err := os.Remove(odir); err != nil {
+				return err
+			}
+		}
+	}
+	// worktrees of the removed clones are still registered in the clones
+	for path := range keep {
+		if filepath.Ext(path) != ".git" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			if err := git(*fetchTimeout, path, "worktree", "prune"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isOwnClone checks if the path is either a bare clone made by cloneRepo, or
+// a worktree of the bare clone next to it.
+func isOwnClone(path string) bool {
+	if filepath.Ext(path) == ".git" {
+		head, err := os.Stat(filepath.Join(path, "HEAD"))
+		if err != nil || head.IsDir() {
+			return false
+		}
+		objects, err := os.Stat(filepath.Join(path, "objects"))
+		return err == nil && objects.IsDir()
+	}
+	// the worktree has a .git file pointing to the metadata of the worktree
+	// inside of the bare clone
+	data, err := ioutil.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return false
+	}
+	gitdir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	bare, err := filepath.Abs(path + ".git")
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(filepath.Clean(gitdir), filepath.Join(bare, "worktrees")+string(filepath.Separator))
+}
+
+// isValidClone checks if the bare clone is a Git repository with a valid HEAD.
+func isValidClone(bare string) bool {
+	_, err := gitOutput(bare, "rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// removeClone removes the bare clone along with its worktree.
+func removeClone(bare, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(bare)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("cooccurrence", findCooccurrence, CooccurrenceReport))
+}
+
+// maxPairs is the number of the most frequent role pairs listed per language.
+const maxPairs = 30
+
+// RolePair is a pair of roles that appear together. For the roles of the same
+// node the pair is ordered by name.
+type RolePair struct {
+	A, B string
+}
+
+// Cooccurrence contains the number of times roles appear together in the
+// fixtures of a driver.
+type Cooccurrence struct {
+	// Node is the number of nodes that have both roles.
+	Node map[RolePair]int
+	// Child is the number of nodes with the first role that have a direct
+	// child with the second one.
+	Child map[RolePair]int
+}
+
+// findCooccurrence counts the roles that appear together on the same node and
+// on the parent and child nodes in the fixtures of a driver. Composite
+// constructs are expected to produce the same role pairs across drivers, e.g.
+// a Function node with a Body child.
+func findCooccurrence(language, pkg string) (*Cooccurrence, error) {
+	c := &Cooccurrence{
+		Node:  make(map[RolePair]int),
+		Child: make(map[RolePair]int),
+	}
+	err := walkFixtureTrees(language, pkg, func(path string, root *Node) {
+		root.Walk(func(n *Node) {
+			roles := distinctRoles(n.Roles)
+			for i, a := range roles {
+				for _, b := range roles[i+1:] {
+					c.Node[RolePair{A: a, B: b}]++
+				}
+			}
+			seen := make(map[RolePair]bool)
+			for _, ch := range n.Children {
+				childRoles := distinctRoles(ch.Roles)
+				for _, a := range roles {
+					for _, b := range childRoles {
+						p := RolePair{A: a, B: b}
+						if !seen[p] {
+							seen[p] = true
+							c.Child[p]++
+						}
+					}
+				}
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// distinctRoles returns sorted roles without duplicates and the mark of
+// unannotated nodes.
+func distinctRoles(roles []string) []string {
+	out := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r != UnannotatedRole {
+			out = append(out, r)
+		}
+	}
+	sort.Strings(out)
+	j := 0
+	for i, r := range out {
+		if i == 0 || r != out[j-1] {
+			out[j] = r
+			j++
+		}
+	}
+	return out[:j]
+}
+
+const cooccurrenceHeader = "" +
+	"# Roles co-occurrence\n\n" +
+	"The most frequent pairs of roles that appear together in the driver " +
+	"fixtures (`*.uast` files): on the same node, and on a node and its direct " +
+	"child. Composite constructs are expected to produce the same pairs in " +
+	"all drivers, e.g. a `Function` node with a `Body` child, thus a pair " +
+	"that is missing in one of the drivers may point to an incomplete mapping.\n"
+
+// CooccurrenceReport renders the most frequent role pairs for each language.
+func CooccurrenceReport(found map[string]*Cooccurrence) string {
+	buf := bytes.NewBuffer([]byte(cooccurrenceHeader))
+	for _, lang := range languages() {
+		c, ok := found[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		buf.WriteString("### Same node\n\n")
+		writePairs(buf, "Role|Role|Nodes", c.Node)
+		buf.WriteString("\n### Parent and child\n\n")
+		writePairs(buf, "Parent|Child|Nodes", c.Child)
+	}
+	return buf.String()
+}
+
+// writePairs writes a table with the most frequent pairs first.
+func writePairs(buf *bytes.Buffer, header string, m map[RolePair]int) {
+	if len(m) == 0 {
+		buf.WriteString("No roles found.\n")
+		return
+	}
+	pairs := make([]RolePair, 0, len(m))
+	for p := range m {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if m[a] != m[b] {
+			return m[a] > m[b]
+		}
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+	if len(pairs) > maxPairs {
+		pairs = pairs[:maxPairs]
+	}
+	buf.WriteString(header + "\n-|-|-\n")
+	for _, p := range pairs {
+		fmt.Fprintf(buf, "%s|%s|%d\n", p.A, p.B, m[p])
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("json", fixtureUsage, func(w io.Writer, roles Roles, _ map[string]map[string][]token.Position) error {
+		return writeCoverage(w, roles.Coverage())
+	}))
+}
+
+// CoverageVersion is the version of the JSON coverage format. It must be
+// changed each time the format changes in an incompatible way, along with
+// adding a new JSON Schema for it.
+const CoverageVersion = 3
+
+//go:embed schema/*.json
+var schemas embed.FS
+
+// writeSchema writes the JSON Schema of the current coverage format.
+func writeSchema(w io.Writer) error {
+	data, err := schemas.ReadFile(fmt.Sprintf("schema/coverage.v%d.json", CoverageVersion))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+var (
+	verbose = flag.Bool("verbose", false, "include the number of nodes with each role in each fixture file into the JSON report")
+)
+
+// Coverage is the machine-readable form of the roles report. It can be saved
+// to compare runs with different SDK or driver versions.
+type Coverage struct {
+	Version   int            `json:"version"`
+	Languages []string       `json:"languages"`
+	Roles     []RoleCoverage `json:"roles"`
+	// Files contains the number of nodes with each role in each fixture
+	// file, per language. Paths are relative to the fixtures directory.
+	Files map[string]map[string]map[string]int `json:"files,omitempty"`
+	// Timings contains the time in seconds spent on each phase of the run
+	// before the report was written, per language.
+	Timings map[string]map[string]float64 `json:"timings,omitempty"`
+}
+
+// RoleCoverage is the number of usages of a role by each language, both in
+// the annotation rules and in the fixtures.
+type RoleCoverage struct {
+	Name      string         `json:"name"`
+	Languages map[string]int `json:"languages,omitempty"`
+	Fixtures  map[string]int `json:"fixtures,omitempty"`
+}
+
+// Coverage returns the machine-readable form of the roles usage.
+func (r Roles) Coverage() *Coverage {
+	c := &Coverage{Version: CoverageVersion, Languages: languages()}
+	for _, role := range r {
+		rc := RoleCoverage{Name: role.Name}
+		for lang, list := range role.Languages {
+			if len(list) == 0 {
+				continue
+			}
+			if rc.Languages == nil {
+				rc.Languages = make(map[string]int)
+			}
+			rc.Languages[lang] = len(list)
+		}
+		for lang, list := range role.Fixtures {
+			if len(list) == 0 {
+				continue
+			}
+			if rc.Fixtures == nil {
+				rc.Fixtures = make(map[string]int)
+			}
+			rc.Fixtures[lang] = len(list)
+		}
+		c.Roles = append(c.Roles, rc)
+	}
+	if *verbose {
+		c.Files = r.fileCounts()
+	}
+	c.Timings = timings.seconds()
+	return c
+}
+
+// fileCounts returns the number of nodes with each role in each fixture file,
+// per language.
+func (r Roles) fileCounts() map[string]map[string]map[string]int {
+	out := make(map[string]map[string]map[string]int)
+	for _, role := range r {
+		for lang, list := range role.Fixtures {
+			for _, pos := range list {
+				files := out[lang]
+				if files == nil {
+					files = make(map[string]map[string]int)
+					out[lang] = files
+				}
+				name := filepath.Base(pos.Filename)
+				if files[name] == nil {
+					files[name] = make(map[string]int)
+				}
+				files[name][role.Name]++
+			}
+		}
+	}
+	return out
+}
+
+func writeCoverage(w io.Writer, c *Coverage) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(c)
+}
+
+func loadCoverage(path string) (*Coverage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c Coverage
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	// newer versions only add fields, thus older reports can be read as well
+	if c.Version < 1 || c.Version > CoverageVersion {
+		return nil, fmt.Errorf("%s: unsupported version: %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// runCompare renders the difference between two saved coverage reports.
+func runCompare(w io.Writer, oldPath, newPath string) error {
+	if oldPath == "" || newPath == "" {
+		return fmt.Errorf("usage: compare old.json new.json")
+	}
+	a, err := loadCoverage(oldPath)
+	if err != nil {
+		return err
+	}
+	b, err := loadCoverage(newPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, CompareReport(a, b))
+	return err
+}
+
+// CompareReport renders roles and languages added and removed between two
+// coverage reports, and the changes of the roles usage by each language.
+func CompareReport(a, b *Coverage) string {
+	buf := bytes.NewBufferString("# Roles coverage changes\n")
+
+	oldRoles, newRoles := rolesByName(a), rolesByName(b)
+	writeNamesDiff(buf, "Roles", keys(oldRoles), keys(newRoles))
+	writeNamesDiff(buf, "Languages", a.Languages, b.Languages)
+
+	changes := diffCoverage(a, b)
+	buf.WriteString("\n## Usage changes\n\n")
+	if len(changes) == 0 {
+		buf.WriteString("No changes.\n")
+		return buf.String()
+	}
+	buf.WriteString("Role|Language|Source|Old|New|Change\n-|-|-|-|-|-\n")
+	for _, c := range changes {
+		fmt.Fprintf(buf, "%s|%s|%s|%d|%d|%s\n",
+			c.Role, strings.Title(c.Language), c.Source, c.Old, c.New, c.Change())
+	}
+	return buf.String()
+}
+
+// UsageChange is a change of the number of usages of a role by a language,
+// either in the annotation rules or in the fixtures.
+type UsageChange struct {
+	Role     string
+	Language string
+	// Source is either "annotations" or "fixtures".
+	Source   string
+	Old, New int
+}
+
+// Change describes the change in a human-readable form.
+func (c UsageChange) Change() string {
+	return countChange(c.Old, c.New)
+}
+
+// countChange describes the change of a number of usages in a human-readable
+// form.
+func countChange(old, new int) string {
+	switch {
+	case old == 0:
+		return "added"
+	case new == 0:
+		return "removed"
+	}
+	return fmt.Sprintf("%+d", new-old)
+}
+
+// diffCoverage returns the changes of the roles usage by each language of the
+// new report, ordered by role name.
+func diffCoverage(a, b *Coverage) []UsageChange {
+	oldRoles, newRoles := rolesByName(a), rolesByName(b)
+	var out []UsageChange
+	for _, name := range keys(newRoles) {
+		or, nr := oldRoles[name], newRoles[name]
+		for _, lang := range b.Languages {
+			for _, kind := range []struct {
+				name     string
+				old, new map[string]int
+			}{
+				{"annotations", or.Languages, nr.Languages},
+				{"fixtures", or.Fixtures, nr.Fixtures},
+			} {
+				o, n := kind.old[lang], kind.new[lang]
+				if o == n {
+					continue
+				}
+				out = append(out, UsageChange{
+					Role: name, Language: lang, Source: kind.name, Old: o, New: n,
+				})
+			}
+		}
+	}
+	return out
+}
+
+func rolesByName(c *Coverage) map[string]RoleCoverage {
+	m := make(map[string]RoleCoverage, len(c.Roles))
+	for _, r := range c.Roles {
+		m[r.Name] = r
+	}
+	return m
+}
+
+func keys(m map[string]RoleCoverage) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// writeNamesDiff writes the names that were added or removed, if any.
+func writeNamesDiff(buf *bytes.Buffer, title string, old, new []string) {
+	inOld, inNew := make(map[string]bool), make(map[string]bool)
+	for _, s := range old {
+		inOld[s] = true
+	}
+	for _, s := range new {
+		inNew[s] = true
+	}
+	var added, removed []string
+	for _, s := range new {
+		if !inOld[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range old {
+		if !inNew[s] {
+			removed = append(removed, s)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "\n## %s\n\n", title)
+	for _, s := range added {
+		fmt.Fprintf(buf, "- added: %s\n", s)
+	}
+	for _, s := range removed {
+		fmt.Fprintf(buf, "- removed: %s\n", s)
+	}
+}
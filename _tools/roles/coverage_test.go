@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testRoles returns roles used both by the annotations and the fixtures.
+func testRoles() Roles {
+	pos := func(file string, line int) token.Position {
+		return token.Position{Filename: file, Line: line}
+	}
+	return Roles{
+		{
+			Name:      "Identifier",
+			Languages: map[string][]token.Position{"python": {pos("annotation.go", 10), pos("annotation.go", 20)}},
+			Fixtures:  map[string][]token.Position{"python": {pos("fixtures/a.py.uast", 3)}},
+			Types:     map[string]map[string]int{"python": {"Name": 1}},
+		},
+		{
+			Name:      "Unused",
+			Languages: map[string][]token.Position{},
+			Fixtures:  map[string][]token.Position{},
+			Types:     map[string]map[string]int{},
+		},
+	}
+}
+
+// coverageVersion returns the coverage report in the given version of the
+// format, i.e. without the fields added later.
+func coverageVersion(c *Coverage, vers int) *Coverage {
+	out := *c
+	out.Version = vers
+	if vers < 2 {
+		out.Files = nil
+	}
+	if vers < 3 {
+		out.Timings = nil
+	}
+	return &out
+}
+
+func TestCoverageSchema(t *testing.T) {
+	*verbose = true
+	defer func() { *verbose = false }()
+	timings.track(PhaseCode, "python")()
+
+	c := testRoles().Coverage()
+	if len(c.Files) == 0 || len(c.Timings) == 0 {
+		t.Fatal("expected the report to contain files and timings")
+	}
+	for vers := 1; vers <= CoverageVersion; vers++ {
+		t.Run(fmt.Sprintf("v%d", vers), func(t *testing.T) {
+			schema := loadTestSchema(t, vers)
+			doc := encodeCoverage(t, coverageVersion(c, vers))
+			if err := validateSchema(schema, schema, doc, "$"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	// the fields added in newer versions are not allowed by the older schemas
+	for vers := 1; vers < CoverageVersion; vers++ {
+		schema := loadTestSchema(t, vers)
+		newer := coverageVersion(c, vers+1)
+		newer.Version = vers
+		doc := encodeCoverage(t, newer)
+		if err := validateSchema(schema, schema, doc, "$"); err == nil {
+			t.Errorf("v%d schema: expected the fields of v%d to be rejected", vers, vers+1)
+		}
+	}
+}
+
+func TestWriteSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSchema(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("coverage.v%d.json", CoverageVersion)
+	if id, _ := schema["$id"].(string); !strings.HasSuffix(id, want) {
+		t.Errorf("unexpected schema id: %q, want %s", id, want)
+	}
+}
+
+func TestLoadCoverage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := testRoles().Coverage()
+	cases := []struct {
+		vers int
+		ok   bool
+	}{
+		{vers: 0, ok: false},
+		{vers: 1, ok: true},
+		{vers: 2, ok: true},
+		{vers: 3, ok: true},
+		{vers: 4, ok: false},
+	}
+	for _, tc := range cases {
+		path := filepath.Join(dir, fmt.Sprintf("v%d.json", tc.vers))
+		var buf bytes.Buffer
+		if err := writeCoverage(&buf, coverageVersion(c, tc.vers)); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loadCoverage(path)
+		switch {
+		case tc.ok && err != nil:
+			t.Errorf("v%d: unexpected error: %v", tc.vers, err)
+		case !tc.ok && err == nil:
+			t.Errorf("v%d: expected an error", tc.vers)
+		case tc.ok && len(got.Roles) != len(c.Roles):
+			t.Errorf("v%d: %d roles loaded, want %d", tc.vers, len(got.Roles), len(c.Roles))
+		}
+	}
+}
+
+func loadTestSchema(t *testing.T, vers int) map[string]interface{} {
+	data, err := schemas.ReadFile(fmt.Sprintf("schema/coverage.v%d.json", vers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("v%d schema: %v", vers, err)
+	}
+	return schema
+}
+
+func encodeCoverage(t *testing.T, c *Coverage) interface{} {
+	var buf bytes.Buffer
+	if err := writeCoverage(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// validateSchema checks the decoded JSON value against the schema. Only the
+// keywords used by the coverage schemas are supported: type, const, minimum,
+// required, properties, additionalProperties, items and local $ref.
+func validateSchema(root, schema map[string]interface{}, v interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		sub := root
+		for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			next, ok := sub[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: unresolved $ref %q", path, ref)
+			}
+			sub = next
+		}
+		return validateSchema(root, sub, v, path)
+	}
+	if want, ok := schema["const"]; ok && want != v {
+		return fmt.Errorf("%s: expected %v, got %v", path, want, v)
+	}
+	if typ, ok := schema["type"].(string); ok {
+		if err := checkType(typ, v); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if min, ok := schema["minimum"].(float64); ok {
+		if n, ok := v.(float64); ok && n < min {
+			return fmt.Errorf("%s: %v is less than %v", path, n, min)
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		list, _ := v.([]interface{})
+		for i, item := range list {
+			if err := validateSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := obj[name.(string)]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+	props, _ := schema["properties"].(map[string]interface{})
+	var keys []string
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sub, ok := props[key].(map[string]interface{})
+		if !ok {
+			switch add := schema["additionalProperties"].(type) {
+			case bool:
+				if !add {
+					return fmt.Errorf("%s: unexpected property %q", path, key)
+				}
+				continue
+			case map[string]interface{}:
+				sub = add
+			default:
+				continue
+			}
+		}
+		if err := validateSchema(root, sub, obj[key], path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkType(typ string, v interface{}) error {
+	ok := false
+	switch typ {
+	case "object":
+		_, ok = v.(map[string]interface{})
+	case "array":
+		_, ok = v.([]interface{})
+	case "string":
+		_, ok = v.(string)
+	case "number":
+		_, ok = v.(float64)
+	case "integer":
+		n, isNum := v.(float64)
+		ok = isNum && n == math.Trunc(n)
+	case "boolean":
+		_, ok = v.(bool)
+	default:
+		return fmt.Errorf("unsupported type in the schema: %s", typ)
+	}
+	if !ok {
+		return fmt.Errorf("expected %s, got %T", typ, v)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func init() {
+	register(newAnalyzer("deprecated", byPackage(findDeprecated), DeprecatedReport))
+}
+
+// SDKPackage is the import path prefix of the SDK packages.
+const SDKPackage = "gopkg.in/bblfsh/sdk.v1"
+
+// DeprecatedUse is a reference to a deprecated SDK identifier.
+type DeprecatedUse struct {
+	Name string
+	Pos  token.Position
+}
+
+// findDeprecated finds references to SDK identifiers marked as deprecated
+// in the normalizer package of a driver.
+func findDeprecated(pkg string) ([]DeprecatedUse, error) {
+	prog, result, err := loadDriver(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []DeprecatedUse
+	for id, obj := range result.Uses {
+		if obj.Pkg() == nil || !strings.HasPrefix(obj.Pkg().Path(), SDKPackage) {
+			continue
+		}
+		src := sourceObject(prog, obj)
+		if src == nil || !isDeprecated(objectDoc(prog, src.Pos())) {
+			continue
+		}
+		out = append(out, DeprecatedUse{
+			Name: obj.Pkg().Name() + "." + obj.Name(),
+			Pos:  prog.Fset.Position(id.Pos()),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Pos, out[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	return out, nil
+}
+
+// sourceObject returns an object loaded from the source code that corresponds
+// to the object from the type checker, or nil if it cannot be found.
+func sourceObject(prog *loader.Program, obj types.Object) types.Object {
+	info := prog.Package(obj.Pkg().Path())
+	if info == nil {
+		return nil
+	}
+	scope := info.Pkg.Scope()
+	if fnc, ok := obj.(*types.Func); ok {
+		if recv := fnc.Type().(*types.Signature).Recv(); recv != nil {
+			t := recv.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			named, ok := t.(*types.Named)
+			if !ok {
+				return nil
+			}
+			tn := scope.Lookup(named.Obj().Name())
+			if tn == nil {
+				return nil
+			}
+			m, _, _ := types.LookupFieldOrMethod(tn.Type(), true, info.Pkg, fnc.Name())
+			return m
+		}
+	}
+	if obj.Parent() != obj.Pkg().Scope() {
+		// struct fields and other non package-level objects
+		return nil
+	}
+	return scope.Lookup(obj.Name())
+}
+
+// objectDoc returns the doc comment of the declaration at a given position.
+func objectDoc(prog *loader.Program, pos token.Pos) *ast.CommentGroup {
+	_, path, _ := prog.PathEnclosingInterval(pos, pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return n.Doc
+		case *ast.ValueSpec:
+			if n.Doc != nil {
+				return n.Doc
+			}
+		case *ast.TypeSpec:
+			if n.Doc != nil {
+				return n.Doc
+			}
+		case *ast.GenDecl:
+			return n.Doc
+		}
+	}
+	return nil
+}
+
+func isDeprecated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if strings.HasPrefix(line, "Deprecated:") {
+			return true
+		}
+	}
+	return false
+}
+
+const deprecatedHeader = "" +
+	"# Deprecated SDK API usage\n\n" +
+	"The list of deprecated SDK identifiers still referenced by the driver " +
+	"normalizers. They need to be migrated before the API can be removed.\n"
+
+// DeprecatedReport renders the list of deprecated identifiers used by each language.
+func DeprecatedReport(uses map[string][]DeprecatedUse) string {
+	buf := bytes.NewBuffer([]byte(deprecatedHeader))
+	for _, lang := range languages() {
+		list, ok := uses[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		if len(list) == 0 {
+			buf.WriteString("No deprecated identifiers are used.\n")
+			continue
+		}
+		for _, u := range list {
+			fmt.Fprintf(buf, "- [ ] `%s` at [%s:%d](%s)\n",
+				u.Name, filepath.Base(u.Pos.Filename), u.Pos.Line,
+				githubLink(lang, "driver/normalizer", u.Pos),
+			)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checkoutRef checks out a given ref of the driver repository to a separate
+// worktree, thus several refs of the same driver can be checked out at once.
+// The worktree directory is named after the driver, as for other clones.
+func checkoutRef(url, ref string) (string, error) {
+	bare, err := fetchRef(url, ref)
+	if err != nil {
+		return "", err
+	}
+	base := reposRoot(*reposDir).dir(url)
+	dir := filepath.Join(base+".refs", sanitizeName(ref), filepath.Base(base))
+	if err := checkoutFetched(bare, dir); err != nil {
+		return "", err
+	}
+	return localDriver(dir)
+}
+
+// runDiff renders the semantic difference between the annotated fixtures of
+// two refs of a driver.
+func runDiff(w io.Writer, lang, oldRef, newRef string) error {
+	if lang == "" || oldRef == "" || newRef == "" {
+		return fmt.Errorf("usage: diff <language> <old-ref> <new-ref>")
+	}
+	url := fmt.Sprintf(GitHubRepoPattern, lang)
+	var versions [2]*FixturesSummary
+	for i, ref := range []string{oldRef, newRef} {
+		end := region("clone")
+		dir, err := checkoutRef(url, ref)
+		end()
+		if err != nil {
+			return err
+		}
+		files, err := annotatedFixtures(dir)
+		if err != nil {
+			return err
+		}
+		versions[i] = &FixturesSummary{Ref: ref, Files: files}
+	}
+	d, err := diffFixtures(versions[0], versions[1])
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, d.Report(lang))
+	return err
+}
+
+// FixturesSummary is the number of nodes with each native type and role in
+// the annotated fixtures of a driver revision.
+type FixturesSummary struct {
+	Ref   string
+	Files []string
+	Types map[string]int
+	Roles map[string]int
+}
+
+func (s *FixturesSummary) add(root *Node) {
+	if s.Types == nil {
+		s.Types = make(map[string]int)
+		s.Roles = make(map[string]int)
+	}
+	root.Walk(func(n *Node) {
+		s.Types[n.InternalType]++
+		for _, r := range n.Roles {
+			s.Roles[r]++
+		}
+	})
+}
+
+// FixtureDiff is the structural change of a single fixture.
+type FixtureDiff struct {
+	Name string
+	// Old and New are the numbers of nodes, zero if the fixture doesn't
+	// exist in the revision.
+	Old, New int
+	// First is the first difference found in the tree, in the depth-first
+	// order.
+	First string
+}
+
+// DriverDiff is the semantic difference between two revisions of a driver.
+type DriverDiff struct {
+	Old, New  *FixturesSummary
+	Fixtures  []FixtureDiff
+	Unchanged int
+}
+
+// diffFixtures decodes the fixtures of both revisions and compares the trees
+// of the fixtures with the same name. Fixtures are decoded one pair at a
+// time, thus only two trees are kept in memory.
+func diffFixtures(a, b *FixturesSummary) (*DriverDiff, error) {
+	byName := func(files []string) map[string]string {
+		m := make(map[string]string, len(files))
+		for _, path := range files {
+			m[filepath.Base(path)] = path
+		}
+		return m
+	}
+	oldFiles, newFiles := byName(a.Files), byName(b.Files)
+	var names []string
+	for name := range oldFiles {
+		names = append(names, name)
+	}
+	for name := range newFiles {
+		if _, ok := oldFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	d := &DriverDiff{Old: a, New: b}
+	for _, name := range names {
+		var roots [2]*Node
+		for i, path := range []string{oldFiles[name], newFiles[name]} {
+			if path == "" {
+				continue
+			}
+			root, err := loadFixtureFile(path)
+			if err != nil {
+				return nil, err
+			}
+			roots[i] = root
+		}
+		fd := FixtureDiff{Name: name}
+		if roots[0] != nil {
+			a.add(roots[0])
+			fd.Old = countNodes(roots[0])
+		}
+		if roots[1] != nil {
+			b.add(roots[1])
+			fd.New = countNodes(roots[1])
+		}
+		if roots[0] != nil && roots[1] != nil {
+			fd.First = firstDifference(roots[0], roots[1], nil)
+			if fd.First == "" {
+				d.Unchanged++
+				continue
+			}
+		}
+		d.Fixtures = append(d.Fixtures, fd)
+	}
+	return d, nil
+}
+
+func countNodes(root *Node) int {
+	n := 0
+	root.Walk(func(*Node) { n++ })
+	return n
+}
+
+// firstDifference compares the native types, roles, tokens and children of
+// the nodes, and describes the first difference found. Positions are not
+// compared, since those change with any edit of the fixture source. It
+// returns an empty string if the trees are the same.
+func firstDifference(a, b *Node, path []string) string {
+	path = append(path, a.InternalType)
+	at := "`" + strings.Join(path, " > ") + "`"
+	switch {
+	case a.InternalType != b.InternalType:
+		return fmt.Sprintf("%s: type changed to `%s`", at, b.InternalType)
+	case !sameRoles(a.Roles, b.Roles):
+		return fmt.Sprintf("%s: roles changed from %s to %s", at, rolesList(a.Roles), rolesList(b.Roles))
+	case a.Token != b.Token:
+		return fmt.Sprintf("%s: token changed from %q to %q", at, a.Token, b.Token)
+	}
+	for i := 0; i < len(a.Children) && i < len(b.Children); i++ {
+		if s := firstDifference(a.Children[i], b.Children[i], path); s != "" {
+			return s
+		}
+	}
+	if len(a.Children) != len(b.Children) {
+		return fmt.Sprintf("%s: %d children instead of %d", at, len(b.Children), len(a.Children))
+	}
+	return ""
+}
+
+// sameRoles checks if the lists contain the same roles, in any order.
+func sameRoles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return rolesList(a) == rolesList(b)
+}
+
+func rolesList(roles []string) string {
+	if len(roles) == 0 {
+		return "none"
+	}
+	list := append([]string(nil), roles...)
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+// Report renders the types and roles gained and lost, and the structural
+// changes of each fixture.
+func (d *DriverDiff) Report(lang string) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# %s driver UAST changes\n\n", strings.Title(lang))
+	fmt.Fprintf(buf, "Changes of the annotated fixtures between `%s` and `%s`.\n", d.Old.Ref, d.New.Ref)
+
+	writeCountsDiff(buf, "Native types", "Type", d.Old.Types, d.New.Types)
+	writeCountsDiff(buf, "Roles", "Role", d.Old.Roles, d.New.Roles)
+
+	buf.WriteString("\n## Fixtures\n\n")
+	if len(d.Fixtures) == 0 {
+		fmt.Fprintf(buf, "No changes in %d fixtures.\n", d.Unchanged)
+		return buf.String()
+	}
+	buf.WriteString("Fixture|Old nodes|New nodes|Change\n-|-|-|-\n")
+	for _, f := range d.Fixtures {
+		change := f.First
+		switch {
+		case f.Old == 0:
+			change = "added"
+		case f.New == 0:
+			change = "removed"
+		}
+		fmt.Fprintf(buf, "%s|%d|%d|%s\n", f.Name, f.Old, f.New, change)
+	}
+	if d.Unchanged != 0 {
+		fmt.Fprintf(buf, "\nFixtures without changes: %d.\n", d.Unchanged)
+	}
+	return buf.String()
+}
+
+// writeCountsDiff writes the table of the names with a different number of
+// nodes in the revisions, if any.
+func writeCountsDiff(buf *bytes.Buffer, title, column string, old, new map[string]int) {
+	var names []string
+	for name, n := range new {
+		if old[name] != n {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	fmt.Fprintf(buf, "\n## %s\n\n", title)
+	if len(names) == 0 {
+		buf.WriteString("No changes.\n")
+		return
+	}
+	sort.Strings(names)
+	fmt.Fprintf(buf, "%s|Old|New|Change\n-|-|-|-\n", column)
+	for _, name := range names {
+		fmt.Fprintf(buf, "`%s`|%d|%d|%s\n", name, old[name], new[name], countChange(old[name], new[name]))
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("dsl", byPackage(findDSLUsage), DSLReport))
+}
+
+// DSLPackages is the list of SDK packages implementing the annotation DSL.
+var DSLPackages = []string{
+	"gopkg.in/bblfsh/sdk.v1/uast/ann",
+	"gopkg.in/bblfsh/sdk.v1/uast/transformer",
+}
+
+// findDSLUsage counts how many times each of the DSL operations is used in
+// the normalizer package of a driver.
+func findDSLUsage(pkg string) (map[string]int, error) {
+	_, result, err := loadDriver(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make(map[string]int)
+	for _, obj := range result.Uses {
+		if name, ok := dslOperation(obj); ok {
+			ops[name]++
+		}
+	}
+
+	return ops, nil
+}
+
+// dslOperation returns the name of a DSL operation referenced by the object,
+// if any.
+func dslOperation(obj types.Object) (string, bool) {
+	fnc, ok := obj.(*types.Func)
+	if !ok || fnc.Pkg() == nil || !isDSLPackage(fnc.Pkg().Path()) {
+		return "", false
+	}
+
+	name := fnc.Pkg().Name() + "." + fnc.Name()
+	if recv := fnc.Type().(*types.Signature).Recv(); recv != nil {
+		// method of a rule builder, i.e. (*ann.Rule).Roles
+		typ := types.TypeString(recv.Type(), func(p *types.Package) string {
+			return p.Name()
+		})
+		name = "(" + typ + ")." + fnc.Name()
+	}
+
+	return name, true
+}
+
+func isDSLPackage(path string) bool {
+	for _, p := range DSLPackages {
+		if p == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+const dslHeader = "" +
+	"# Annotation DSL usage\n\n" +
+	"The table shows how many times each operation of the SDK annotation DSL " +
+	"is used by the driver normalizers.\n\n"
+
+// DSLReport renders the usage of the DSL operations for each language.
+func DSLReport(usage map[string]map[string]int) string {
+	seen := make(map[string]bool)
+	var ops []string
+	for _, m := range usage {
+		for op := range m {
+			if !seen[op] {
+				seen[op] = true
+				ops = append(ops, op)
+			}
+		}
+	}
+	sort.Strings(ops)
+
+	langs := languages()
+	buf := bytes.NewBuffer([]byte(dslHeader))
+	buf.WriteString("Operation")
+	for _, lang := range langs {
+		buf.WriteString("|" + strings.Title(lang))
+	}
+	buf.WriteString("\n-" + strings.Repeat("|-", len(langs)) + "\n")
+
+	for _, op := range ops {
+		fmt.Fprintf(buf, "`%s`", op)
+		for _, lang := range langs {
+			var cnt string
+			if n := usage[lang][op]; n != 0 {
+				cnt = fmt.Sprint(n)
+			}
+			fmt.Fprintf(buf, "|%s", cnt)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("examples", withoutRoles(findExamples), func(w io.Writer, roles Roles, examples map[string]map[string]*Example) error {
+		return printReport(w, func() string { return roles.ExamplesReport(examples) })
+	}))
+}
+
+const (
+	// maxExampleSize is the maximal length of the source snippet of an example.
+	maxExampleSize = 300
+	// maxExampleDepth limits the depth of the node tree shown in an example.
+	maxExampleDepth = 3
+)
+
+// Example is a node with a given role found in fixtures, along with the
+// source code it was parsed from.
+type Example struct {
+	Path   string
+	Line   int
+	Source string
+	Node   *Node
+}
+
+// fixtureSource returns the source file of a fixture, i.e. x.py.source for
+// x.py.uast. Sources parsed by bblfshd are returned as is.
+func fixtureSource(path string) string {
+	if strings.HasSuffix(path, ".source") {
+		return path
+	}
+	return strings.TrimSuffix(path, ".uast") + ".source"
+}
+
+// nodeSource returns the source code of the node, or false if the node has no
+// positions or they are out of the source bounds.
+func nodeSource(n *Node, src []byte) (string, bool) {
+	if n.StartPosition == nil || n.EndPosition == nil {
+		return "", false
+	}
+	start, end := n.StartPosition.Offset, n.EndPosition.Offset
+	if start < 0 || end <= start || end > len(src) {
+		return "", false
+	}
+	return string(src[start:end]), true
+}
+
+// findExamples returns the smallest example of each role found in fixtures
+// of a driver, indexed by role name.
+func findExamples(language, pkg string) (map[string]*Example, error) {
+	out := make(map[string]*Example)
+	// every fixture is generated from a source file, thus a missing one
+	// means the fixtures layout is not supported
+	var srcErr error
+	err := walkFixtureTrees(language, pkg, func(path string, root *Node) {
+		if srcErr != nil {
+			return
+		}
+		src, err := ioutil.ReadFile(fixtureSource(path))
+		if err != nil {
+			srcErr = fmt.Errorf("cannot read the source of the fixture: %v", err)
+			return
+		}
+		root.Walk(func(n *Node) {
+			if len(n.Roles) == 0 {
+				return
+			}
+			code, ok := nodeSource(n, src)
+			if !ok || len(code) > maxExampleSize {
+				return
+			}
+			for _, r := range n.Roles {
+				if ex, ok := out[r]; ok && len(ex.Source) <= len(code) {
+					continue
+				}
+				out[r] = &Example{Path: path, Line: n.Line, Source: code, Node: n}
+			}
+		})
+	})
+	if err == nil {
+		err = srcErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// writeNode writes the node in a short form, similar to the fixtures format.
+func writeNode(buf *bytes.Buffer, n *Node, depth int) {
+	indent := strings.Repeat(".  ", depth)
+	fmt.Fprintf(buf, "%s%s\n", indent, nodeSummary(n))
+	if len(n.Children) == 0 {
+		return
+	}
+	if depth+1 >= maxExampleDepth {
+		fmt.Fprintf(buf, "%s.  ...\n", indent)
+		return
+	}
+	for _, c := range n.Children {
+		writeNode(buf, c, depth+1)
+	}
+}
+
+const examplesHeader = "" +
+	"# Roles examples\n\n" +
+	"The smallest examples of each role found in the driver fixtures: the " +
+	"source code and the annotated node parsed from it.\n"
+
+// ExamplesReport renders an example of each role for each language.
+func (r Roles) ExamplesReport(examples map[string]map[string]*Example) string {
+	buf := bytes.NewBuffer([]byte(examplesHeader))
+	langs := languages()
+	for _, role := range r {
+		found := false
+		for _, lang := range langs {
+			if examples[lang][role.Name] != nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n%s\n", role.Name, strings.TrimSpace(role.Doc))
+		for _, lang := range langs {
+			ex := examples[lang][role.Name]
+			if ex == nil {
+				continue
+			}
+			fmt.Fprintf(buf, "\n### %s\n\n```%s\n%s\n```\n\n```\n", strings.Title(lang), lang, ex.Source)
+			writeNode(buf, ex.Node, 0)
+			pos := token.Position{Filename: ex.Path, Line: ex.Line}
+			fmt.Fprintf(buf, "```\n\nFound in [%s](%s).\n", filepath.Base(ex.Path),
+				githubLink(lang, fixturesDir(OfficialDriver[lang]), pos))
+		}
+	}
+	return buf.String()
+}
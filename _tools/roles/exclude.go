@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+)
+
+var (
+	excludeList = flag.String("exclude", "", "comma-separated list of drivers to leave out of cloning and the report, e.g. cuda,other")
+)
+
+// excluded checks if the driver for the language is excluded by the user.
+func excluded(lang string) bool {
+	if *excludeList == "" {
+		return false
+	}
+	for _, s := range strings.Split(*excludeList, ",") {
+		if strings.TrimSpace(s) == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeDrivers removes the drivers excluded by the user from the list.
+func excludeDrivers() {
+	for lang := range OfficialDriver {
+		if excluded(lang) {
+			log.Printf("%s driver is excluded", lang)
+			delete(OfficialDriver, lang)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("fixtures", fixtureUsage, func(w io.Writer, roles Roles, unknown map[string]map[string][]token.Position) error {
+		return printReport(w, func() string { return roles.FixturesReport(unknownRoles(unknown)) })
+	}))
+}
+
+// FixturesDir is the directory of the driver repository with the integration
+// tests fixtures.
+const FixturesDir = "fixtures"
+
+// NormalizerDir is the directory of the driver repository with the
+// normalizer package.
+const NormalizerDir = "driver/normalizer"
+
+// isLocal checks if the driver is specified by a path to a local checkout
+// instead of the import path of the normalizer package.
+func isLocal(pkg string) bool {
+	return build.IsLocalImport(pkg) || filepath.IsAbs(pkg)
+}
+
+// normalizerFiles returns the source files of the normalizer package of
+// a local driver checkout.
+func normalizerFiles(dir string) ([]string, error) {
+	dir = filepath.Join(dir, filepath.FromSlash(NormalizerDir))
+	p, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range p.GoFiles {
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files, nil
+}
+
+// driverDir returns the root directory of the driver repository given the
+// import path of its normalizer package or a path to a local checkout.
+func driverDir(pkg string) (string, error) {
+	if isLocal(pkg) {
+		return pkg, nil
+	}
+	p, err := build.Import(pkg, "", build.FindOnly)
+	if err != nil {
+		return "", err
+	}
+	// normalizer package is located at <driver>/driver/normalizer
+	return filepath.Dir(filepath.Dir(p.Dir)), nil
+}
+
+// findFixtures returns the list of fixture files of a driver with a given
+// extension. Semantic fixtures (*.sem.uast) are only returned if requested
+// explicitly, even though they match *.uast as well.
+func findFixtures(pkg, ext string) ([]string, error) {
+	dir, err := driverDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, fixturesDir(pkg), "*"+ext))
+	if err != nil || strings.HasSuffix(ext, ".sem.uast") {
+		return files, err
+	}
+	var out []string
+	for _, path := range files {
+		if !strings.HasSuffix(path, ".sem.uast") {
+			out = append(out, path)
+		}
+	}
+	return out, nil
+}
+
+var (
+	distinct = flag.Bool("distinct", false, "show the number of fixture files and distinct native types with the role in the fixtures report, not only a mark")
+	jobs     = flag.Int("j", runtime.NumCPU(), "number of fixture files to decode in parallel")
+	stream   = flag.Bool("stream", false, "decode fixture files one node at a time to limit the memory usage (disables parallel decoding and the cache), also applies to the semantic fixtures checked by validate")
+)
+
+// UnknownRoles contains the positions of roles that are not defined in the
+// SDK, per language and role name.
+type UnknownRoles map[string]map[string][]token.Position
+
+func (u UnknownRoles) add(language, name string, pos token.Position) {
+	m, ok := u[language]
+	if !ok {
+		m = make(map[string][]token.Position)
+		u[language] = m
+	}
+	m[name] = append(m[name], pos)
+}
+
+// findFixtureUsage finds which roles are used in the annotated UAST fixtures
+// of a driver. Roles that are not in the list are recorded as unknown.
+func findFixtureUsage(language, pkg string, roles Roles, unknown UnknownRoles) error {
+	return walkFixtures(language, pkg, func(path string, n *Node) {
+		for _, r := range n.Roles {
+			pos := token.Position{Filename: path, Line: n.Line}
+			if !roles.UsedInFixture(r, language, n.InternalType, pos) {
+				unknown.add(language, r, pos)
+			}
+		}
+	})
+}
+
+// fixtureUsage is like findFixtureUsage, but returns the unknown roles of the
+// driver instead of recording them.
+func fixtureUsage(language, pkg string, roles Roles) (map[string][]token.Position, error) {
+	unknown := make(UnknownRoles)
+	if err := findFixtureUsage(language, pkg, roles, unknown); err != nil {
+		return nil, err
+	}
+	return unknown[language], nil
+}
+
+// unknownRoles collects the unknown roles returned by fixtureUsage for each
+// language, omitting the languages without any.
+func unknownRoles(byLang map[string]map[string][]token.Position) UnknownRoles {
+	unknown := make(UnknownRoles)
+	for lang, m := range byLang {
+		if len(m) != 0 {
+			unknown[lang] = m
+		}
+	}
+	return unknown
+}
+
+// walkFixtures calls fn for each node of the annotated UAST fixtures of
+// a driver. The order of the nodes is not defined.
+func walkFixtures(language, pkg string, fn func(path string, n *Node)) error {
+	if *stream && *bblfshd == "" {
+		return scanFixtures(pkg, fn)
+	}
+	return walkFixtureTrees(language, pkg, func(path string, root *Node) {
+		root.Walk(func(n *Node) {
+			fn(path, n)
+		})
+	})
+}
+
+// walkFixtureTrees calls fn for the root node of each annotated UAST fixture
+// of a driver. If bblfshd address is set, fixture sources are parsed by it
+// instead of reading *.uast files.
+func walkFixtureTrees(language, pkg string, fn func(path string, root *Node)) error {
+	defer timings.track(PhaseFixtures, language)()
+	if *bblfshd != "" {
+		return walkLive(language, pkg, fn)
+	}
+	files, err := annotatedFixtures(pkg)
+	if err != nil {
+		return err
+	}
+
+	// files are decoded in parallel, but fn is called in the same order
+	// as the files are listed, thus the results do not depend on timing
+	type result struct {
+		root *Node
+		err  error
+	}
+	results := make([]chan result, len(files))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+	n := *jobs
+	if n < 1 {
+		n = 1
+	}
+	// token is released only when the tree is passed to fn, thus the
+	// number of decoded trees kept in memory is limited as well
+	tokens := make(chan struct{}, n)
+	// the memory budget is held for the same time, but it's acquired
+	// according to the file size
+	budget := fixturesBudget()
+	sizes := make([]int64, len(files))
+	var (
+		done     = make(chan struct{})
+		stopped  = make(chan struct{})
+		launched int
+		consumed int
+	)
+	go func() {
+		defer close(stopped)
+		for i, path := range files {
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return
+			}
+			if fi, err := os.Stat(path); err == nil {
+				sizes[i] = fi.Size()
+			}
+			if !budget.acquire(sizes[i], done) {
+				return
+			}
+			launched++
+			go func(i int, path string) {
+				defer region("decode")()
+				root, err := loadFixtureFile(path)
+				results[i] <- result{root: root, err: err}
+			}(i, path)
+		}
+	}()
+	defer func() {
+		close(done)
+		budget.wake()
+		<-stopped
+		// files that are still being decoded hold the budget
+		for i := consumed; i < launched; i++ {
+			<-results[i]
+			budget.release(sizes[i])
+		}
+	}()
+	for i, path := range files {
+		r := <-results[i]
+		<-tokens
+		budget.release(sizes[i])
+		consumed = i + 1
+		if r.err != nil {
+			atomic.AddInt64(&metrics.FixtureFailures, 1)
+			return r.err
+		}
+		fn(path, r.root)
+	}
+	return nil
+}
+
+// scanFixtures calls fn for each node of the annotated UAST fixtures of
+// a driver without decoding the whole trees.
+func scanFixtures(pkg string, fn func(path string, n *Node)) error {
+	defer timings.track(PhaseFixtures, languageOf(pkg))()
+	files, err := annotatedFixtures(pkg)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := scanFixtureFile(path, fn); err != nil {
+			atomic.AddInt64(&metrics.FixtureFailures, 1)
+			return err
+		}
+	}
+	return nil
+}
+
+func scanFixtureFile(path string, fn func(path string, n *Node)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = ScanFixture(f, func(n *Node) {
+		fn(path, n)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// loadFixtureFile decodes a fixture file.
+func loadFixtureFile(path string) (*Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root, err := loadFixture(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return root, nil
+}
+
+const fixturesHeader = "" +
+	"# Roles in fixtures\n\n" +
+	"The table shows which roles are assigned by the driver annotation rules " +
+	"and which of them actually appear in the driver fixtures (`*.uast` files).\n\n"
+
+// FixturesReport renders the roles coverage of annotation rules and fixtures,
+// followed by the list of unknown roles found in fixtures of each language.
+func (r Roles) FixturesReport(unknown UnknownRoles) string {
+	buf := bytes.NewBuffer([]byte(fixturesHeader))
+	if *distinct {
+		buf.WriteString("Fixture cells show the number of nodes with the role, the number of " +
+			"fixture files and the number of distinct native types they belong to. " +
+			"A high number of nodes in a single file or of a single type means that " +
+			"the role is exercised by a single construct.\n\n")
+	}
+
+	langs := languages()
+	buf.WriteString("Role")
+	for _, lang := range langs {
+		fmt.Fprintf(buf, "|%s annotations|%s fixtures", strings.Title(lang), strings.Title(lang))
+	}
+	buf.WriteString("\n-" + strings.Repeat("|-", 2*len(langs)) + "\n")
+
+	for _, role := range r {
+		fmt.Fprintf(buf, "[%s](roles.md#%s)", role.Name, strings.ToLower(role.Name))
+		for _, lang := range langs {
+			var ann, fix string
+			if role.IsUsedBy(lang) {
+				pos := firstPosition(role.Languages[lang])
+				ann = fmt.Sprintf("[✓](%s)", githubLink(lang, "driver/normalizer", pos))
+			}
+			if role.IsUsedInFixtures(lang) {
+				pos := firstPosition(role.Fixtures[lang])
+				mark := "✓"
+				if *distinct {
+					mark = fmt.Sprintf("%d/%d/%d", len(role.Fixtures[lang]),
+						role.FixtureFiles(lang), role.FixtureTypes(lang))
+				}
+				fix = fmt.Sprintf("[%s](%s)", mark, githubLink(lang, fixturesDir(OfficialDriver[lang]), pos))
+			}
+			fmt.Fprintf(buf, "|%s|%s", ann, fix)
+		}
+		buf.WriteString("\n")
+	}
+
+	writeUnknownRoles(buf, unknown)
+	return buf.String()
+}
+
+func writeUnknownRoles(w *bytes.Buffer, unknown UnknownRoles) {
+	if len(unknown) == 0 {
+		return
+	}
+	w.WriteString("\n## Unknown roles\n\n" +
+		"Roles found in fixtures that are not defined in the SDK. " +
+		"Most likely those are typos, or the fixtures were generated by " +
+		"a different SDK version.\n")
+	for _, lang := range languages() {
+		m := unknown[lang]
+		if len(m) == 0 {
+			continue
+		}
+		var names []string
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(w, "\n### %s\n\n", strings.Title(lang))
+		for _, name := range names {
+			pos := m[name]
+			fmt.Fprintf(w, "- `%s`: %d times, first in `%s:%d`\n",
+				name, len(pos), filepath.Base(pos[0].Filename), pos[0].Line)
+		}
+	}
+}
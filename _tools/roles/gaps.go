@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// UnannotatedRole is a role assigned by the SDK to nodes that were not
+// matched by any annotation rule.
+const UnannotatedRole = "Unannotated"
+
+// Gap is a single item of work needed to improve a driver annotations.
+type Gap struct {
+	// ID is a stable identifier of the gap. It is the same between runs,
+	// thus project trackers can update existing items instead of creating
+	// duplicates.
+	ID       string
+	Kind     string
+	Language string
+	Title    string
+	Body     string
+}
+
+const (
+	// GapMissingRole is a role that is never assigned by driver annotations.
+	GapMissingRole = "missing-role"
+	// GapUnannotated is a native node type left unannotated in fixtures.
+	GapUnannotated = "unannotated"
+)
+
+func newGap(kind, language, name, title, body string) Gap {
+	h := sha1.Sum([]byte(kind + "/" + language + "/" + name))
+	return Gap{
+		ID:       hex.EncodeToString(h[:6]),
+		Kind:     kind,
+		Language: language,
+		Title:    title,
+		Body:     body,
+	}
+}
+
+// findUnannotated returns the number of unannotated nodes of each internal
+// type found in fixtures of a driver.
+func findUnannotated(language, pkg string) (map[string]int, error) {
+	types := make(map[string]int)
+	err := walkFixtures(language, pkg, func(path string, n *Node) {
+		for _, r := range n.Roles {
+			if r == UnannotatedRole {
+				types[n.InternalType]++
+				break
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// findGaps returns the list of gaps for all drivers.
+func findGaps(roles Roles) ([]Gap, error) {
+	var gaps []Gap
+	for _, lang := range languages() {
+		for _, role := range roles {
+			if role.Name == UnannotatedRole || role.IsUsedBy(lang) {
+				continue
+			}
+			gaps = append(gaps, newGap(GapMissingRole, lang, role.Name,
+				fmt.Sprintf("%s: annotate nodes with %s role", strings.Title(lang), role.Name),
+				strings.TrimSpace(role.Doc),
+			))
+		}
+
+		types, err := findUnannotated(lang, OfficialDriver[lang])
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for name := range types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			gaps = append(gaps, newGap(GapUnannotated, lang, name,
+				fmt.Sprintf("%s: annotate %s nodes", strings.Title(lang), name),
+				fmt.Sprintf("%d nodes of %s type are left unannotated in fixtures.", types[name], name),
+			))
+		}
+	}
+	return gaps, nil
+}
+
+// writeGapsJSON writes the gaps as a list of items that can be imported to
+// GitHub Projects.
+func writeGapsJSON(w io.Writer, gaps []Gap) error {
+	type item struct {
+		ID     string   `json:"id"`
+		Title  string   `json:"title"`
+		Body   string   `json:"body"`
+		Labels []string `json:"labels"`
+	}
+	items := make([]item, 0, len(gaps))
+	for _, g := range gaps {
+		items = append(items, item{
+			ID:     g.ID,
+			Title:  g.Title,
+			Body:   g.Body,
+			Labels: []string{g.Language, g.Kind},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(items)
+}
+
+// writeGapsCSV writes the gaps in the CSV format accepted by Jira importer.
+func writeGapsCSV(w io.Writer, gaps []Gap) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"External ID", "Summary", "Description", "Labels", "Labels"})
+	for _, g := range gaps {
+		cw.Write([]string{g.ID, g.Title, g.Body, g.Language, g.Kind})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// queryStep is a single step of a fixtures query. It matches nodes that have
+// all the listed names either as roles or as an internal type.
+type queryStep struct {
+	names []string
+	// child is set if the node must be a direct child of the node matched
+	// by the previous step; otherwise it can be any descendant
+	child bool
+}
+
+// parseQuery parses a simplified XPath-like query:
+//
+//	Identifier                    nodes with the Identifier role or type
+//	Function+Declaration          nodes with both roles
+//	Function//Identifier          identifiers inside of functions
+//	Call/Identifier               identifiers that are direct children of calls
+//	/File/*                       direct children of the root node
+func parseQuery(q string) ([]queryStep, error) {
+	var steps []queryStep
+	// the first step can be at any depth unless the query starts with "/"
+	child := false
+	if strings.HasPrefix(q, "/") && !strings.HasPrefix(q, "//") {
+		child = true
+	}
+	q = strings.TrimLeft(q, "/")
+	for q != "" {
+		i := strings.Index(q, "/")
+		step := q
+		if i >= 0 {
+			step = q[:i]
+		}
+		if step == "" {
+			return nil, fmt.Errorf("empty query step")
+		}
+		s := queryStep{child: child}
+		if step != "*" {
+			s.names = strings.Split(step, "+")
+		}
+		steps = append(steps, s)
+		if i < 0 {
+			break
+		}
+		q = q[i+1:]
+		child = true
+		if strings.HasPrefix(q, "/") {
+			q, child = q[1:], false
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	return steps, nil
+}
+
+func (s queryStep) matches(n *Node) bool {
+	for _, name := range s.names {
+		found := n.InternalType == name
+		for _, r := range n.Roles {
+			if found {
+				break
+			}
+			found = r == name
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchQuery checks if the last node of the path matches the query. The path
+// contains the node and all its ancestors starting from the root.
+func matchQuery(steps []queryStep, path []*Node) bool {
+	last := len(steps) - 1
+	if len(path) == 0 || !steps[last].matches(path[len(path)-1]) {
+		return false
+	}
+	parents := path[:len(path)-1]
+	if last == 0 {
+		// the first step is either relative to the root or at any depth
+		return !steps[0].child || len(parents) == 0
+	}
+	if steps[last].child {
+		return matchQuery(steps[:last], parents)
+	}
+	for i := len(parents); i > 0; i-- {
+		if matchQuery(steps[:last], parents[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGrep prints the fixture nodes of all drivers that match the query.
+func runGrep(w io.Writer, query string) error {
+	steps, err := parseQuery(query)
+	if err != nil {
+		return err
+	}
+	for _, lang := range languages() {
+		err := walkFixtureTrees(lang, OfficialDriver[lang], func(path string, root *Node) {
+			var stack []*Node
+			var walk func(n *Node)
+			walk = func(n *Node) {
+				stack = append(stack, n)
+				if matchQuery(steps, stack) {
+					fmt.Fprintf(w, "%s: %s:%d: %s\n", lang, filepath.Base(path), n.Line, nodeSummary(n))
+				}
+				for _, c := range n.Children {
+					walk(c)
+				}
+				stack = stack[:len(stack)-1]
+			}
+			walk(root)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeSummary returns a single-line description of a node.
+func nodeSummary(n *Node) string {
+	s := n.InternalType
+	if len(n.Roles) != 0 {
+		s += " [" + strings.Join(n.Roles, ", ") + "]"
+	}
+	if n.Token != "" {
+		s += fmt.Sprintf(" %q", n.Token)
+	}
+	return s
+}
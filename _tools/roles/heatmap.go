@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer[struct{}]("heatmap", nil, func(w io.Writer, roles Roles, _ map[string]struct{}) error {
+		stop := timings.track(PhaseRender, "")
+		html, err := roles.Heatmap()
+		stop()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, html)
+		return err
+	}))
+}
+
+var heatmapTemplate = template.Must(template.New("heatmap").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Roles usage heatmap</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; font-size: 12px; }
+th, td { border: 1px solid #ddd; padding: 2px 6px; }
+td.cell { text-align: right; min-width: 3em; }
+</style>
+</head>
+<body>
+<h1>Roles usage heatmap</h1>
+<p>The color intensity of each cell reflects the number of times the role is used by the driver annotation rules.</p>
+<table>
+<tr><th>Role</th>{{range .Languages}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><th><a href="roles.html#{{.Anchor}}">{{.Name}}</a></th>{{range .Cells}}<td class="cell" style="background-color: {{.Color}}" title="used {{.Count}} times">{{if .Count}}{{.Count}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type heatmapCell struct {
+	Count int
+	Color template.CSS
+}
+
+type heatmapRow struct {
+	Name   string
+	Anchor string
+	Cells  []heatmapCell
+}
+
+// heatColor returns the cell color for a given usage count. The scale is
+// logarithmic, since a few roles are used much more often than the others.
+func heatColor(n, max int) template.CSS {
+	if n == 0 || max == 0 {
+		return "#fff"
+	}
+	a := math.Log1p(float64(n)) / math.Log1p(float64(max))
+	// never fully transparent, thus rarely used roles are still visible
+	return template.CSS(fmt.Sprintf("rgba(0, 128, 0, %.2f)", 0.1+0.9*a))
+}
+
+// Heatmap renders the roles usage as an HTML table colored by the number of
+// usages of each role by each language.
+func (r Roles) Heatmap() (string, error) {
+	table, langs := r, languages()
+	if *hideEmpty {
+		table, langs = r.nonEmpty(langs)
+	}
+	table, langs = table.sorted(*sortRoles, langs, *sortLangs)
+
+	max := 0
+	for _, role := range table {
+		for _, lang := range langs {
+			if n := len(role.Languages[lang]); n > max {
+				max = n
+			}
+		}
+	}
+	data := struct {
+		Languages []string
+		Rows      []heatmapRow
+	}{}
+	for _, lang := range langs {
+		data.Languages = append(data.Languages, strings.Title(lang))
+	}
+	for _, role := range table {
+		row := heatmapRow{Name: role.Name, Anchor: strings.ToLower(role.Name)}
+		for _, lang := range langs {
+			n := len(role.Languages[lang])
+			row.Cells = append(row.Cells, heatmapCell{Count: n, Color: heatColor(n, max)})
+		}
+		data.Rows = append(data.Rows, row)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := heatmapTemplate.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
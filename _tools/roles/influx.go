@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("influx", fixtureUsage, func(w io.Writer, roles Roles, _ map[string]map[string][]token.Position) error {
+		_, err := fmt.Fprint(w, render(func() string { return roles.LineProtocol(time.Now()) }))
+		return err
+	}))
+}
+
+// InfluxMeasurement is the name of the measurement with the coverage numbers
+// in the line protocol output.
+const InfluxMeasurement = "roles_coverage"
+
+// LineProtocol renders the coverage numbers of each driver in the InfluxDB
+// line protocol, one line per driver, thus scheduled runs can be appended to
+// a time-series database and tracked over time, e.g. in Grafana.
+func (r Roles) LineProtocol(t time.Time) string {
+	buf := bytes.NewBuffer(nil)
+	for _, lang := range languages() {
+		var ann, fix int
+		for _, role := range r {
+			if role.IsUsedBy(lang) {
+				ann++
+			}
+			if role.IsUsedInFixtures(lang) {
+				fix++
+			}
+		}
+		fmt.Fprintf(buf, "%s,language=%s roles=%di,annotations=%di,fixtures=%di,score=%.2f %d\n",
+			InfluxMeasurement, escapeTag(lang), len(r), ann, fix, r.Score(lang), t.UnixNano())
+	}
+	return buf.String()
+}
+
+// escapeTag escapes the characters that are special in the tag values of the
+// line protocol, e.g. in the names of the forked drivers.
+func escapeTag(s string) string {
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LegacyFixturesDir is the directory with the fixtures used by older drivers.
+const LegacyFixturesDir = "tests"
+
+var layoutFlags stringList
+
+func init() {
+	flag.Var(&layoutFlags, "fixtures-layout", "override the fixtures location of a driver as language=dir[,ext], e.g. java=tests,.uast (can be repeated)")
+}
+
+// fixtureLayout is the location of the annotated fixtures of a driver.
+type fixtureLayout struct {
+	// Dir is the fixtures directory relative to the driver root.
+	Dir string
+	// Ext is the extension of the annotated UAST fixtures.
+	Ext string
+}
+
+// layouts are the per-driver overrides of the fixtures location.
+var layouts = make(map[string]fixtureLayout)
+
+// parseLayouts parses the fixtures layout overrides in the form of
+// language=dir[,ext]. The directory may be empty to only change the extension.
+func parseLayouts(list []string) error {
+	for _, s := range list {
+		i := strings.Index(s, "=")
+		if i <= 0 {
+			return fmt.Errorf("invalid fixtures layout %q, expected language=dir[,ext]", s)
+		}
+		lang, l := s[:i], fixtureLayout{Dir: s[i+1:]}
+		if j := strings.Index(l.Dir, ","); j >= 0 {
+			l.Dir, l.Ext = l.Dir[:j], l.Dir[j+1:]
+		}
+		// native fixtures have no roles, and semantic ones are YAML documents,
+		// thus neither can be decoded as the annotated UAST
+		if l.Ext != "" && (!strings.HasSuffix(l.Ext, ".uast") || strings.HasSuffix(l.Ext, ".sem.uast")) {
+			return fmt.Errorf("invalid fixtures layout %q: extension must be .uast, semantic fixtures are not supported", s)
+		}
+		layouts[lang] = l
+	}
+	return nil
+}
+
+// languageOf returns the language of a driver given the import path of its
+// normalizer package or a path to a local checkout.
+func languageOf(pkg string) string {
+	for lang, p := range OfficialDriver {
+		if p == pkg {
+			return lang
+		}
+	}
+	if isLocal(pkg) {
+		return driverLanguage(pkg)
+	}
+	return ""
+}
+
+// fixturesDir returns the fixtures directory of a driver relative to its root.
+// Unless overridden, the legacy directory is used if the driver has no
+// fixtures directory of the current layout.
+func fixturesDir(pkg string) string {
+	if l := layouts[languageOf(pkg)]; l.Dir != "" {
+		return l.Dir
+	}
+	root, err := driverDir(pkg)
+	if err != nil {
+		return FixturesDir
+	}
+	for _, dir := range []string{FixturesDir, LegacyFixturesDir} {
+		if fi, err := os.Stat(filepath.Join(root, dir)); err == nil && fi.IsDir() {
+			return dir
+		}
+	}
+	return FixturesDir
+}
+
+// annotatedFixtures returns the list of annotated UAST fixtures of a driver.
+func annotatedFixtures(pkg string) ([]string, error) {
+	ext := layouts[languageOf(pkg)].Ext
+	if ext == "" {
+		ext = ".uast"
+	}
+	return findFixtures(pkg, ext)
+}
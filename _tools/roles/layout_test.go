@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLayouts(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []string
+		want map[string]fixtureLayout
+		err  bool
+	}{
+		{
+			name: "dir",
+			in:   []string{"java=tests"},
+			want: map[string]fixtureLayout{"java": {Dir: "tests"}},
+		},
+		{
+			name: "dir and ext",
+			in:   []string{"java=tests,.uast"},
+			want: map[string]fixtureLayout{"java": {Dir: "tests", Ext: ".uast"}},
+		},
+		{
+			name: "ext only",
+			in:   []string{"python=,.annotated.uast"},
+			want: map[string]fixtureLayout{"python": {Ext: ".annotated.uast"}},
+		},
+		{
+			name: "multiple",
+			in:   []string{"java=tests", "python=fixtures/v1"},
+			want: map[string]fixtureLayout{"java": {Dir: "tests"}, "python": {Dir: "fixtures/v1"}},
+		},
+		{
+			name: "no language",
+			in:   []string{"=tests"},
+			err:  true,
+		},
+		{
+			name: "no separator",
+			in:   []string{"java"},
+			err:  true,
+		},
+		{
+			name: "native",
+			in:   []string{"java=tests,.native"},
+			err:  true,
+		},
+		{
+			name: "semantic",
+			in:   []string{"java=fixtures,.sem.uast"},
+			err:  true,
+		},
+	}
+	defer func() { layouts = make(map[string]fixtureLayout) }()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			layouts = make(map[string]fixtureLayout)
+			err := parseLayouts(c.in)
+			if c.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(layouts, c.want) {
+				t.Errorf("unexpected layouts: %v, want %v", layouts, c.want)
+			}
+		})
+	}
+}
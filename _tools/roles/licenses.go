@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("licenses", byPackage(findLicenses), LicensesReport))
+}
+
+// License is a license file found in the driver repository.
+type License struct {
+	// Path is the path of the file relative to the driver root.
+	Path string
+	// Name is the SPDX identifier of the license, or empty if it was not
+	// recognized.
+	Name string
+}
+
+// Copyleft checks if the license requires derived works to be distributed
+// under the same terms.
+func (l License) Copyleft() bool {
+	return strings.Contains(l.Name, "GPL")
+}
+
+// DriverLicenses contains the license of the driver itself and the licenses
+// of the native parsers and other third-party code it includes.
+type DriverLicenses struct {
+	Driver []License
+	Vendor []License
+}
+
+var licenseFileRe = regexp.MustCompile(`(?i)^(licen[cs]e|copying|unlicense)(\.[a-z]+)?$`)
+
+// licensePatterns recognize the license by its text. More specific
+// patterns must go first.
+var licensePatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"AGPL-3.0", regexp.MustCompile(`GNU AFFERO GENERAL PUBLIC LICENSE`)},
+	{"LGPL-3.0", regexp.MustCompile(`GNU LESSER GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"LGPL-2.1", regexp.MustCompile(`GNU LESSER GENERAL PUBLIC LICENSE\s+Version 2\.1|GNU LIBRARY GENERAL PUBLIC LICENSE`)},
+	{"GPL-3.0", regexp.MustCompile(`GNU GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"GPL-2.0", regexp.MustCompile(`GNU GENERAL PUBLIC LICENSE\s+Version 2`)},
+	{"MPL-2.0", regexp.MustCompile(`Mozilla Public License,? [Vv]ersion 2\.0`)},
+	{"EPL-1.0", regexp.MustCompile(`Eclipse Public License - v 1\.0`)},
+	{"EPL-2.0", regexp.MustCompile(`Eclipse Public License - v 2\.0`)},
+	{"Apache-2.0", regexp.MustCompile(`Apache License,?\s+Version 2\.0`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?s)Redistribution and use in source and binary forms.*Neither the name`)},
+	{"BSD-2-Clause", regexp.MustCompile(`Redistribution and use in source and binary forms`)},
+	{"MIT", regexp.MustCompile(`Permission is hereby granted, free of charge`)},
+	{"ISC", regexp.MustCompile(`Permission to use, copy, modify, and/or distribute this software for any`)},
+	{"Unlicense", regexp.MustCompile(`This is free and unencumbered software released into the public domain`)},
+}
+
+// detectLicense returns the SPDX identifier of the license text, or an empty
+// string if it's not recognized.
+func detectLicense(text string) string {
+	// line breaks and indentation differ between copies of the same license
+	text = strings.Join(strings.Fields(text), " ")
+	for _, p := range licensePatterns {
+		if p.re.MatchString(text) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+// findLicenses finds the license files in the driver repository. Files in the
+// root directory are the license of the driver, the rest belongs to the
+// third-party code included into it, such as the native parser.
+func findLicenses(pkg string) (*DriverLicenses, error) {
+	dir, err := driverDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+	lic := &DriverLicenses{}
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if fi.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !licenseFileRe.MatchString(fi.Name()) {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		l := License{Path: filepath.ToSlash(rel), Name: detectLicense(string(data))}
+		if filepath.Dir(rel) == "." {
+			lic.Driver = append(lic.Driver, l)
+		} else {
+			lic.Vendor = append(lic.Vendor, l)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lic, nil
+}
+
+const licensesHeader = "" +
+	"# Licenses\n\n" +
+	"The table shows the license of each driver and the licenses of the " +
+	"third-party code included into its repository, such as the native parser. " +
+	"Licenses are detected by the text of `LICENSE` and `COPYING` files.\n\n"
+
+// LicensesReport renders the licenses summary for each language, followed by
+// the list of drivers that include copyleft code.
+func LicensesReport(licenses map[string]*DriverLicenses) string {
+	buf := bytes.NewBuffer([]byte(licensesHeader))
+	buf.WriteString("Language|Driver|Third-party\n")
+	buf.WriteString("-|-|-\n")
+	for _, lang := range languages() {
+		lic, ok := licenses[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "%s|%s|%s\n", strings.Title(lang),
+			licenseNames(lic.Driver), licenseNames(lic.Vendor))
+	}
+
+	buf.WriteString("\n## Copyleft\n\n")
+	found := false
+	for _, lang := range languages() {
+		lic, ok := licenses[lang]
+		if !ok {
+			continue
+		}
+		for _, l := range append(lic.Driver, lic.Vendor...) {
+			if l.Copyleft() {
+				found = true
+				fmt.Fprintf(buf, "- %s: `%s` (%s)\n", strings.Title(lang), l.Path, l.Name)
+			}
+		}
+	}
+	if !found {
+		buf.WriteString("None of the drivers include copyleft code.\n")
+	}
+	return buf.String()
+}
+
+// licenseNames returns a sorted list of distinct license names. Unrecognized
+// licenses are listed as "unknown".
+func licenseNames(list []License) string {
+	if len(list) == 0 {
+		return "-"
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, l := range list {
+		name := l.Name
+		if name == "" {
+			name = "unknown"
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	bblfsh "gopkg.in/bblfsh/client-go.v2"
+	"gopkg.in/bblfsh/sdk.v1/protocol"
+	"gopkg.in/bblfsh/sdk.v1/uast"
+)
+
+var (
+	bblfshd      = flag.String("bblfshd", "", "address of bblfshd to parse fixture sources with, instead of reading *.uast files")
+	parseTimeout = flag.Duration("timeout", time.Minute, "timeout for parsing a single fixture source with bblfshd")
+)
+
+var (
+	clientOnce sync.Once
+	client     *bblfsh.Client
+	clientErr  error
+)
+
+// bblfshClient returns a client connected to bblfshd.
+func bblfshClient() (*bblfsh.Client, error) {
+	clientOnce.Do(func() {
+		client, clientErr = bblfsh.NewClient(*bblfshd)
+	})
+	return client, clientErr
+}
+
+// walkLive parses fixture sources (*.source files) of a driver with bblfshd
+// and calls fn for each of the returned UASTs.
+func walkLive(language, pkg string, fn func(path string, root *Node)) error {
+	cli, err := bblfshClient()
+	if err != nil {
+		return err
+	}
+	files, err := findFixtures(pkg, ".source")
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *parseTimeout)
+		res, err := cli.NewParseRequest().Language(language).Content(string(data)).DoWithContext(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if res.Status != protocol.Ok || res.UAST == nil {
+			return fmt.Errorf("%s: parsing failed: %s", path, strings.Join(res.Errors, "; "))
+		}
+		fn(path, convertNode(res.UAST))
+	}
+	return nil
+}
+
+// convertNode converts the UAST returned by bblfshd to the same structure as
+// the one decoded from fixtures. Lines of the nodes refer to the source file.
+func convertNode(n *uast.Node) *Node {
+	out := &Node{
+		InternalType:  n.InternalType,
+		Token:         n.Token,
+		Properties:    n.Properties,
+		StartPosition: convertPosition(n.StartPosition),
+		EndPosition:   convertPosition(n.EndPosition),
+	}
+	if out.StartPosition != nil {
+		out.Line = out.StartPosition.Line
+	}
+	for _, r := range n.Roles {
+		out.Roles = append(out.Roles, r.String())
+	}
+	for _, c := range n.Children {
+		out.Children = append(out.Children, convertNode(c))
+	}
+	return out
+}
+
+func convertPosition(p *uast.Position) *Position {
+	if p == nil {
+		return nil
+	}
+	return &Position{Offset: int(p.Offset), Line: int(p.Line), Col: int(p.Col)}
+}
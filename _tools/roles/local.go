@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// stringList is a flag that can be specified multiple times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+var localDirs stringList
+
+func init() {
+	flag.Var(&localDirs, "local", "analyze a local driver checkout instead of the official drivers (can be repeated)")
+}
+
+// localDriver returns the absolute path of a local driver checkout, thus it
+// cannot be confused with an import path.
+func localDriver(dir string) (string, error) {
+	return filepath.Abs(dir)
+}
+
+// useLocalDrivers replaces the list of official drivers with the local
+// checkouts. The language is derived from the directory name.
+func useLocalDrivers(dirs []string) error {
+	drivers := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		path, err := localDriver(dir)
+		if err != nil {
+			return err
+		}
+		lang := driverLanguage(path)
+		if _, ok := drivers[lang]; ok {
+			return fmt.Errorf("%s: driver for %s is specified multiple times", dir, lang)
+		}
+		drivers[lang] = path
+	}
+	OfficialDriver = drivers
+	return nil
+}
@@ -3,15 +3,25 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/go/loader"
+	"gopkg.in/bblfsh/sdk.v1/manifest"
 )
 
 const (
@@ -21,6 +31,10 @@ const (
 	RoleType = UASTPackage + ".Role"
 	// GitHubFilePattern route to the annotation.go file at GitHub
 	GitHubFilePattern = "https://github.com/bblfsh/%s-driver/blob/master/driver/normalizer/annotation.go"
+	// GitHubRepoPattern URL of the driver repository at GitHub
+	GitHubRepoPattern = "https://github.com/bblfsh/%s-driver"
+	// GitHubLinePattern route to a line of a file in the driver repository at GitHub
+	GitHubLinePattern = "https://github.com/bblfsh/%s-driver/blob/master/%s#L%d"
 )
 
 var (
@@ -31,18 +45,240 @@ var (
 	}
 )
 
+var (
+	report    = flag.String("report", "annotations", "report to generate (annotations, fixtures, parity, positions, modes, stats, dsl, deprecated, unannotated, unmapped, licenses, bench, vet, cooccurrence, tokens, examples, heatmap, app, todo, json, influx or gh-summary)")
+	sdkDir    = flag.String("sdk", "", "load roles from the SDK source checkout in this directory instead of GOPATH")
+	transpose = flag.Bool("transpose", false, "render languages as rows and roles as columns")
+	hideEmpty = flag.Bool("hide-empty", false, "omit roles and languages without any usage from the table")
+	marks     = flag.String("marks", "check", "table cells style (check, emoji or count)")
+	sortRoles = flag.String("sort-roles", "name", "roles order in the table (name or usage)")
+	sortLangs = flag.String("sort-languages", "name", "languages order in the table (name, coverage or status)")
+	group     = flag.Bool("group", false, "group roles in the table by category, cannot be used with -transpose")
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer[struct{}]("annotations", nil, func(w io.Writer, roles Roles, _ map[string]struct{}) error {
+		md := render(roles.String)
+		if _, err := fmt.Fprintln(w, md); err != nil {
+			return err
+		}
+		if *archiveDir == "" {
+			return nil
+		}
+		if err := archiveReport(*archiveDir, roles, md, time.Now()); err != nil {
+			return err
+		}
+		return pruneArchive(*archiveDir, *archiveKeep)
+	}))
+}
+
 func main() {
-	roles, err := findRoles()
+	flag.Parse()
+	metrics.Start = time.Now()
+	stopProfiles := startProfiles()
+	defer stopProfiles()
+	defer timings.write(os.Stderr)
+
+	var roles Roles
+	// metrics are pushed for the report runs, including the failed ones
+	pushMetrics := func() {
+		if *pushgateway == "" || flag.Arg(0) != "" || *watchDir != "" {
+			return
+		}
+		if err := metrics.push(*pushgateway, *pushJob, roles); err != nil {
+			log.Printf("warning: cannot push metrics: %v", err)
+		}
+	}
+	defer pushMetrics()
+	// deferred calls are not run on exit
+	exit := func(code int) {
+		pushMetrics()
+		timings.write(os.Stderr)
+		stopProfiles()
+		os.Exit(code)
+	}
+	fatal := func(err error) {
+		log.Print(err)
+		exit(1)
+	}
+	if err := checkTableFlags(); err != nil {
+		fatal(err)
+	}
+	if len(localDirs) != 0 {
+		if err := useLocalDrivers(localDirs); err != nil {
+			fatal(err)
+		}
+	}
+	if flag.Arg(0) == "clean" {
+		// repositories are not cloned, since they may be corrupted
+		if err := runClean(extraRepos); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if len(extraRepos) != 0 {
+		repaired, err := addRepos(extraRepos)
+		metrics.Repaired = len(repaired)
+		if err != nil {
+			fatal(err)
+		}
+	}
+	excludeDrivers()
+	if err := parseLayouts(layoutFlags); err != nil {
+		fatal(err)
+	}
+	if *weightsFile != "" {
+		if err := loadWeights(*weightsFile); err != nil {
+			fatal(err)
+		}
+	}
+	if *watchDir != "" {
+		if err := runWatch(*watchDir); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	switch flag.Arg(0) {
+	case "":
+	case "validate":
+		ok, err := runValidate()
+		if err != nil {
+			fatal(err)
+		} else if !ok {
+			exit(1)
+		}
+		return
+	case "export":
+		if err := runExport(os.Stdout, flag.Arg(1)); err != nil {
+			fatal(err)
+		}
+		return
+	case "compare":
+		if err := runCompare(os.Stdout, flag.Arg(1), flag.Arg(2)); err != nil {
+			fatal(err)
+		}
+		return
+	case "diff":
+		if err := runDiff(os.Stdout, flag.Arg(1), flag.Arg(2), flag.Arg(3)); err != nil {
+			fatal(err)
+		}
+		return
+	case "changelog":
+		if err := runChangelog(os.Stdout, flag.Args()[1:]); err != nil {
+			fatal(err)
+		}
+		return
+	case "schema":
+		if err := writeSchema(os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	case "show":
+		if err := runShow(os.Stdout, flag.Arg(1)); err != nil {
+			fatal(err)
+		}
+		return
+	case "grep":
+		if err := runGrep(os.Stdout, flag.Arg(1)); err != nil {
+			fatal(err)
+		}
+		return
+	case "serve":
+		if err := runServe(); err != nil {
+			fatal(err)
+		}
+		return
+	default:
+		fatal(fmt.Errorf("unknown command: %s", flag.Arg(0)))
+	}
+
+	if a, ok := analyzers[*report]; ok {
+		for l, pkg := range OfficialDriver {
+			if err := a.Run(l, pkg); err != nil {
+				fatal(err)
+			}
+		}
+		fmt.Println(render(a.Render))
+		return
+	}
+
+	a, ok := rolesAnalyzers[*report]
+	if !ok {
+		fatal(fmt.Errorf("unknown report: %s", *report))
+	}
+
+	var err error
+	roles, err = findRoles()
 	if err != nil {
-		panic(err)
+		fatal(err)
 	}
 
 	for l, pkg := range OfficialDriver {
-		findUsage(l, pkg, roles)
+		if err := findUsage(l, pkg, roles); err != nil {
+			// most likely the driver depends on a different SDK version
+			log.Printf("warning: cannot load %s driver, its roles won't be reported: %v", l, err)
+			metrics.LoadFailures++
+		}
 	}
 
-	fmt.Println(roles)
+	for l, pkg := range OfficialDriver {
+		if err := a.Run(l, pkg, roles); err != nil {
+			fatal(err)
+		}
+	}
+	if err := a.Render(os.Stdout, roles); err != nil {
+		fatal(err)
+	}
+	if *notifyWebhook != "" {
+		if err := notifyChanges(*notifyWebhook, *previous, roles); err != nil {
+			log.Printf("warning: cannot send the notification: %v", err)
+		}
+	}
+	if *minCoverage > 0 && !checkCoverage(roles, *minCoverage) {
+		exit(1)
+	}
+}
 
+// runExport writes the annotation gaps of all drivers in a given format
+// (jira or github), or exports the coverage matrix to a Google Sheet (sheets).
+func runExport(w io.Writer, format string) error {
+	// check the format first, loading the drivers takes a while
+	switch format {
+	case "jira", "github", "sheets":
+	default:
+		return fmt.Errorf("unknown export format: %q", format)
+	}
+	roles, err := findRoles()
+	if err != nil {
+		return err
+	}
+	for _, l := range languages() {
+		if err := findUsage(l, OfficialDriver[l], roles); err != nil {
+			log.Printf("warning: cannot load %s driver, its roles won't be exported: %v", l, err)
+		}
+	}
+	if format == "sheets" {
+		return exportSheet(w, roles)
+	}
+	gaps, err := findGaps(roles)
+	if err != nil {
+		return err
+	}
+	if format == "jira" {
+		return writeGapsCSV(w, gaps)
+	}
+	return writeGapsJSON(w, gaps)
+}
+
+// languages returns a sorted list of official drivers languages.
+func languages() []string {
+	var list []string
+	for lang := range OfficialDriver {
+		list = append(list, lang)
+	}
+	sort.Strings(list)
+	return list
 }
 
 // findRoles find the roles defined at the uast package.
@@ -50,7 +286,15 @@ func findRoles() (Roles, error) {
 	var out Roles
 
 	conf := loader.Config{ParserMode: parser.ParseComments}
-	conf.Import(UASTPackage)
+	if *sdkDir != "" {
+		files, err := sdkFiles(*sdkDir)
+		if err != nil {
+			return nil, err
+		}
+		conf.CreateFromFilenames(UASTPackage, files...)
+	} else {
+		conf.Import(UASTPackage)
+	}
 	prog, err := conf.Load()
 	if err != nil {
 		return nil, err
@@ -67,12 +311,32 @@ func findRoles() (Roles, error) {
 			Name:      obj.Name(),
 			Doc:       findDoc(prog, obj.Pos()).Text(),
 			Languages: make(map[string][]token.Position),
+			Fixtures:  make(map[string][]token.Position),
+			Types:     make(map[string]map[string]int),
 		})
 	}
 
+	if *rolesFile != "" {
+		return loadRolesFile(*rolesFile, out)
+	}
 	return out, nil
 }
 
+// sdkFiles returns the list of source files of the uast package in the SDK
+// checkout located in dir.
+func sdkFiles(dir string) ([]string, error) {
+	dir = filepath.Join(dir, "uast")
+	p, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range p.GoFiles {
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files, nil
+}
+
 func findDoc(prog *loader.Program, pos token.Pos) *ast.CommentGroup {
 	_, path, _ := prog.PathEnclosingInterval(pos, pos)
 	for _, n := range path {
@@ -90,20 +354,9 @@ func findDoc(prog *loader.Program, pos token.Pos) *ast.CommentGroup {
 // findUsage finds in the normalizer package of a driver which roles are being
 // used.
 func findUsage(language, pkg string, roles Roles) error {
-	conf := loader.Config{ParserMode: parser.ParseComments}
-	conf.Import(pkg)
-	prog, err := conf.Load()
-	if err != nil {
-		return err
-	}
-
-	info := prog.Package(pkg)
-	result := &types.Info{
-		Uses: make(map[*ast.Ident]types.Object),
-	}
-
-	tconf := types.Config{Importer: importer.Default()}
-	_, err = tconf.Check("", prog.Fset, info.Files, result)
+	defer region("annotations")()
+	defer timings.track(PhaseCode, language)()
+	prog, result, err := loadDriver(pkg)
 	if err != nil {
 		return err
 	}
@@ -113,18 +366,69 @@ func findUsage(language, pkg string, roles Roles) error {
 			continue
 		}
 
-		roles.UsedBy(obj.Name(), language, prog.Fset.Position(id.Pos()))
+		pos := prog.Fset.Position(id.Pos())
+		if !roles.UsedBy(obj.Name(), language, pos) {
+			log.Printf("warning: %s: unknown role %s, try a newer SDK with -sdk", pos, obj.Name())
+		}
 	}
 
 	return nil
 
 }
 
+// driverPackage returns the normalizer package loaded by loadDriver.
+func driverPackage(prog *loader.Program, pkg string) *loader.PackageInfo {
+	if isLocal(pkg) {
+		return prog.Created[0]
+	}
+	return prog.Package(pkg)
+}
+
+// loadDriver loads and type checks the normalizer package of a driver.
+// The pkg is either an import path of the package, or a path to a local
+// driver checkout.
+func loadDriver(pkg string) (*loader.Program, *types.Info, error) {
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	if isLocal(pkg) {
+		files, err := normalizerFiles(pkg)
+		if err != nil {
+			return nil, nil, err
+		}
+		conf.CreateFromFilenames("normalizer", files...)
+	} else {
+		conf.Import(pkg)
+	}
+	prog, err := conf.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := driverPackage(prog, pkg)
+	result := &types.Info{
+		Uses:  make(map[*ast.Ident]types.Object),
+		Types: make(map[ast.Expr]types.TypeAndValue),
+	}
+
+	tconf := types.Config{Importer: importer.Default()}
+	_, err = tconf.Check("", prog.Fset, info.Files, result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return prog, result, nil
+}
+
 // Role contains the relevant information of a Role definition
 type Role struct {
 	Name      string
 	Doc       string
 	Languages map[string][]token.Position
+	// Fixtures contains the positions in the fixtures of each language
+	// where the role is used.
+	Fixtures map[string][]token.Position
+	// Types contains the number of nodes of each native type that have
+	// the role in the fixtures of each language.
+	Types map[string]map[string]int
 }
 
 func (r *Role) IsUsedBy(language string) bool {
@@ -136,11 +440,48 @@ func (r *Role) IsUsedBy(language string) bool {
 	return false
 }
 
+func (r *Role) IsUsedInFixtures(language string) bool {
+	return len(r.Fixtures[language]) > 0
+}
+
+// FixtureFiles returns the number of fixture files of the language where
+// the role is used.
+func (r *Role) FixtureFiles(language string) int {
+	files := make(map[string]bool)
+	for _, pos := range r.Fixtures[language] {
+		files[pos.Filename] = true
+	}
+	return len(files)
+}
+
+// FixtureTypes returns the number of distinct native types that have the
+// role in the fixtures of the language.
+func (r *Role) FixtureTypes(language string) int {
+	return len(r.Types[language])
+}
+
 // Roles is a list of roles.
 type Roles []*Role
 
+// clean returns a copy of the roles without any usage information.
+func (r Roles) clean() Roles {
+	out := make(Roles, 0, len(r))
+	for _, role := range r {
+		out = append(out, &Role{
+			Name:      role.Name,
+			Doc:       role.Doc,
+			Languages: make(map[string][]token.Position),
+			Fixtures:  make(map[string][]token.Position),
+			Types:     make(map[string]map[string]int),
+		})
+	}
+	return out
+}
+
 // UsedBy adds the given language to the list of language using a specific role.
-func (r Roles) UsedBy(name, language string, pos token.Position) {
+// It returns false if the role is not in the list.
+func (r Roles) UsedBy(name, language string, pos token.Position) bool {
+	found := false
 	for _, role := range r {
 		if role.Name != name {
 			continue
@@ -151,7 +492,31 @@ func (r Roles) UsedBy(name, language string, pos token.Position) {
 		}
 
 		role.Languages[language] = append(role.Languages[language], pos)
+		found = true
+	}
+
+	return found
+}
+
+// UsedInFixture records that the given role appears in a fixture of a language
+// on a node of the given native type. It returns false if the role is not in
+// the list.
+func (r Roles) UsedInFixture(name, language, typ string, pos token.Position) bool {
+	found := false
+	for _, role := range r {
+		if role.Name != name {
+			continue
+		}
+
+		role.Fixtures[language] = append(role.Fixtures[language], pos)
+		if role.Types[language] == nil {
+			role.Types[language] = make(map[string]int)
+		}
+		role.Types[language][typ]++
+		found = true
 	}
+
+	return found
 }
 
 const documentHeader = "" +
@@ -161,16 +526,134 @@ const documentHeader = "" +
 
 func (r Roles) String() string {
 	buf := bytes.NewBuffer([]byte(documentHeader))
-	writeTableHeader(buf)
-	writeTableBody(buf, r)
+	table, langs := r, languages()
+	if *hideEmpty {
+		table, langs = r.nonEmpty(langs)
+	}
+	table, langs = table.sorted(*sortRoles, langs, *sortLangs)
+	writeScores(buf, r, langs)
+	buf.WriteString("## Usage\n\n")
+	switch {
+	case *transpose:
+		writeTransposedTable(buf, table, langs)
+	case *group:
+		writeTableHeader(buf, langs)
+		writeGroupedTableBody(buf, table, langs)
+	default:
+		writeTableHeader(buf, langs)
+		writeTableBody(buf, table, langs)
+	}
 	writeList(buf, r)
+	writeMissing(buf, r)
 
 	return buf.String()
 }
 
-func writeTableHeader(w *bytes.Buffer) {
+// sorted returns the roles and languages ordered by the given criteria.
+// Roles can be sorted by "name" or by "usage" (number of languages using
+// the role). Languages can be sorted by "name", by "coverage" (number of
+// roles used) or by "status" (development status in the driver manifest,
+// the most stable first). Sorting is stable, thus equal items keep the name
+// order.
+func (r Roles) sorted(byRoles string, langs []string, byLangs string) (Roles, []string) {
+	r = append(Roles{}, r...)
+	langs = append([]string{}, langs...)
+	switch byRoles {
+	case "usage":
+		sort.SliceStable(r, func(i, j int) bool {
+			return r[i].usage(langs) > r[j].usage(langs)
+		})
+	}
+	switch byLangs {
+	case "coverage":
+		cov := make(map[string]int)
+		for _, lang := range langs {
+			for _, role := range r {
+				if role.IsUsedBy(lang) {
+					cov[lang]++
+				}
+			}
+		}
+		sort.SliceStable(langs, func(i, j int) bool {
+			return cov[langs[i]] > cov[langs[j]]
+		})
+	case "status":
+		rank := make(map[string]int)
+		for _, lang := range langs {
+			rank[lang] = driverStatusRank(lang)
+		}
+		sort.SliceStable(langs, func(i, j int) bool {
+			return rank[langs[i]] > rank[langs[j]]
+		})
+	}
+	return r, langs
+}
+
+// driverStatusRank returns the rank of the development status in the
+// manifest of the driver. Drivers without a readable manifest are ranked
+// below all statuses with a warning.
+func driverStatusRank(lang string) int {
+	dir, err := driverDir(OfficialDriver[lang])
+	if err != nil {
+		log.Printf("warning: cannot find %s driver, its status is unknown: %v", lang, err)
+		return -1
+	}
+	f, err := os.Open(filepath.Join(dir, manifest.Filename))
+	if err != nil {
+		log.Printf("warning: cannot read %s driver manifest, its status is unknown: %v", lang, err)
+		return -1
+	}
+	defer f.Close()
+	var m manifest.Manifest
+	if err := m.Decode(f); err != nil {
+		log.Printf("warning: cannot read %s driver manifest, its status is unknown: %v", lang, err)
+		return -1
+	}
+	return m.Status.Rank()
+}
+
+// usage returns the number of languages using the role.
+func (r *Role) usage(langs []string) int {
+	n := 0
+	for _, lang := range langs {
+		if r.IsUsedBy(lang) {
+			n++
+		}
+	}
+	return n
+}
+
+// nonEmpty returns roles used by at least one of the languages, and languages
+// using at least one of the roles.
+func (r Roles) nonEmpty(langs []string) (Roles, []string) {
+	var (
+		roles Roles
+		used  = make(map[string]bool)
+	)
+	for _, role := range r {
+		found := false
+		for _, lang := range langs {
+			if role.IsUsedBy(lang) {
+				used[lang] = true
+				found = true
+			}
+		}
+		if found {
+			roles = append(roles, role)
+		}
+	}
+	var out []string
+	for _, lang := range langs {
+		if used[lang] {
+			out = append(out, lang)
+		}
+	}
+	return roles, out
+}
+
+func writeTableHeader(w *bytes.Buffer, langs []string) {
 	var list []string
-	for lang := range OfficialDriver {
+	for _, lang := range langs {
 		list = append(list, strings.Title(lang))
 	}
 
@@ -178,16 +661,35 @@ func writeTableHeader(w *bytes.Buffer) {
 	w.WriteString(strings.Repeat("-|-", len(list)) + "\n")
 }
 
-func writeTableBody(w *bytes.Buffer, r Roles) {
+func writeTableBody(w *bytes.Buffer, r Roles, langs []string) {
+	writeTableRows(w, r, langs)
+	fmt.Fprintf(w, "\n\n")
+}
+
+func writeTableRows(w *bytes.Buffer, r Roles, langs []string) {
 	for _, role := range r {
 		fmt.Fprintf(w, "[%s](#%s)", role.Name, strings.ToLower(role.Name))
-		for lang := range OfficialDriver {
-			var used string
-			if role.IsUsedBy(lang) {
-				used = "✓"
-			}
+		for _, lang := range langs {
+			fmt.Fprintf(w, "|%s", usedCell(role, lang))
+		}
 
-			fmt.Fprintf(w, "|%s", used)
+		fmt.Fprintf(w, "\n")
+	}
+}
+
+// writeTransposedTable writes the table with languages as rows and roles
+// as columns.
+func writeTransposedTable(w *bytes.Buffer, r Roles, langs []string) {
+	w.WriteString("Language")
+	for _, role := range r {
+		fmt.Fprintf(w, "|[%s](#%s)", role.Name, strings.ToLower(role.Name))
+	}
+	w.WriteString("\n-" + strings.Repeat("|-", len(r)) + "\n")
+
+	for _, lang := range langs {
+		w.WriteString(strings.Title(lang))
+		for _, role := range r {
+			fmt.Fprintf(w, "|%s", usedCell(role, lang))
 		}
 
 		fmt.Fprintf(w, "\n")
@@ -196,6 +698,77 @@ func writeTableBody(w *bytes.Buffer, r Roles) {
 	fmt.Fprintf(w, "\n\n")
 }
 
+// checkTableFlags checks the flags of the table style, thus a typo is not
+// silently rendered in the default style.
+func checkTableFlags() error {
+	switch *marks {
+	case "check", "emoji", "count":
+	default:
+		return fmt.Errorf("unknown -marks style: %q", *marks)
+	}
+	switch *sortRoles {
+	case "name", "usage":
+	default:
+		return fmt.Errorf("unknown -sort-roles order: %q", *sortRoles)
+	}
+	switch *sortLangs {
+	case "name", "coverage", "status":
+	default:
+		return fmt.Errorf("unknown -sort-languages order: %q", *sortLangs)
+	}
+	if *group && *transpose {
+		// roles are the columns of the transposed table, thus they cannot
+		// be grouped by rows
+		return fmt.Errorf("-group cannot be used with -transpose")
+	}
+	return nil
+}
+
+// usedCell returns the content of the table cell for the role and the language.
+func usedCell(role *Role, lang string) string {
+	list := role.Languages[lang]
+	if len(list) == 0 {
+		switch *marks {
+		case "emoji":
+			return "❌"
+		case "check":
+			return "✗"
+		}
+		return ""
+	}
+	var mark string
+	switch *marks {
+	case "emoji":
+		mark = "✅"
+	case "count":
+		mark = fmt.Sprint(len(list))
+	case "check":
+		mark = "✓"
+	}
+	url := githubLink(lang, "driver/normalizer", firstPosition(list))
+	// the title is shown as a tooltip with the number of usages
+	return fmt.Sprintf(`[%s](%s "used %d times")`, mark, url, len(list))
+}
+
+// firstPosition returns the first position in the file order.
+func firstPosition(list []token.Position) token.Position {
+	first := list[0]
+	for _, p := range list[1:] {
+		if p.Filename < first.Filename ||
+			(p.Filename == first.Filename && p.Line < first.Line) {
+			first = p
+		}
+	}
+	return first
+}
+
+// githubLink returns a link to a line of a file in the driver repository.
+// The dir is the directory of the file relative to the repository root.
+func githubLink(language, dir string, pos token.Position) string {
+	return fmt.Sprintf(GitHubLinePattern, language,
+		path.Join(dir, filepath.Base(pos.Filename)), pos.Line)
+}
+
 func writeList(w *bytes.Buffer, r Roles) {
 	for _, role := range r {
 
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUsedCell(t *testing.T) {
+	role := &Role{
+		Name: "Identifier",
+		Languages: map[string][]token.Position{
+			"python": {{Filename: "driver/normalizer/annotation.go", Line: 10}},
+		},
+	}
+	cases := []struct {
+		marks        string
+		used, unused string
+	}{
+		{marks: "check", used: "[✓]", unused: "✗"},
+		{marks: "emoji", used: "[✅]", unused: "❌"},
+		{marks: "count", used: "[1]", unused: ""},
+	}
+	old := *marks
+	defer func() { *marks = old }()
+	for _, c := range cases {
+		*marks = c.marks
+		if err := checkTableFlags(); err != nil {
+			t.Errorf("%s: %v", c.marks, err)
+		}
+		if cell := usedCell(role, "python"); !strings.HasPrefix(cell, c.used) {
+			t.Errorf("%s: expected the used cell to start with %q, got %q", c.marks, c.used, cell)
+		}
+		if cell := usedCell(role, "java"); cell != c.unused {
+			t.Errorf("%s: expected the unused cell %q, got %q", c.marks, c.unused, cell)
+		}
+	}
+	*marks = "checks"
+	if err := checkTableFlags(); err == nil {
+		t.Error("expected an error for an unknown style")
+	}
+}
+
+func TestCheckTableFlags(t *testing.T) {
+	oldGroup, oldTranspose := *group, *transpose
+	defer func() { *group, *transpose = oldGroup, oldTranspose }()
+	for _, c := range []struct {
+		group, transpose, err bool
+	}{
+		{},
+		{group: true},
+		{transpose: true},
+		{group: true, transpose: true, err: true},
+	} {
+		*group, *transpose = c.group, c.transpose
+		if err := checkTableFlags(); (err != nil) != c.err {
+			t.Errorf("group: %v, transpose: %v: unexpected error: %v", c.group, c.transpose, err)
+		}
+	}
+}
+
+func TestSortLanguagesByStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "roles-status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	old := OfficialDriver
+	defer func() { OfficialDriver = old }()
+	OfficialDriver = make(map[string]string)
+	for lang, status := range map[string]string{
+		"bash":   "alpha",
+		"go":     "beta",
+		"java":   "beta",
+		"python": "",
+	} {
+		path := filepath.Join(dir, lang)
+		OfficialDriver[lang] = path
+		if status == "" {
+			// no manifest
+			continue
+		}
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		data := "language = \"" + lang + "\"\nstatus = \"" + status + "\"\n"
+		if err := ioutil.WriteFile(filepath.Join(path, "manifest.toml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, langs := Roles{}.sorted("name", []string{"bash", "go", "java", "python"}, "status")
+	if exp := []string{"go", "java", "bash", "python"}; !reflect.DeepEqual(langs, exp) {
+		t.Errorf("expected %v, got %v", exp, langs)
+	}
+}
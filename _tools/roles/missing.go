@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxMissing is the number of missing roles listed for each language.
+const maxMissing = 10
+
+// missingRoles returns the roles not used by the language, but used by other
+// languages. Roles used by more languages are listed first.
+func (r Roles) missingRoles(lang string, langs []string) Roles {
+	var out Roles
+	for _, role := range r {
+		if role.Name == UnannotatedRole {
+			// assigned by the SDK, not by the annotation rules
+			continue
+		}
+		if !role.IsUsedBy(lang) && role.usage(langs) != 0 {
+			out = append(out, role)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].usage(langs) > out[j].usage(langs)
+	})
+	return out
+}
+
+// writeMissing writes the most common roles each of the languages does not
+// use yet.
+func writeMissing(w *bytes.Buffer, r Roles) {
+	langs := languages()
+	w.WriteString("## Missing roles\n\n" +
+		"The roles each driver does not assign yet, while other drivers do. " +
+		"Roles used by more drivers are listed first. Those are good candidates " +
+		"for new annotation rules, [help us](../community.md) to add them!\n")
+	for _, lang := range langs {
+		missing := r.missingRoles(lang, langs)
+		if len(missing) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %s\n\n", strings.Title(lang))
+		for i, role := range missing {
+			if i == maxMissing {
+				fmt.Fprintf(w, "- and %d more\n", len(missing)-maxMissing)
+				break
+			}
+			n, drivers := role.usage(langs), "drivers"
+			if n == 1 {
+				drivers = "driver"
+			}
+			fmt.Fprintf(w, "- [%s](#%s), used by %d %s\n",
+				role.Name, strings.ToLower(role.Name), n, drivers)
+		}
+	}
+	w.WriteString("\n")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("modes", byPackage(findModes), ModesReport))
+}
+
+// ParseModes is the list of fixture extensions for each parse mode.
+var ParseModes = []struct {
+	Name string
+	Ext  string
+}{
+	{Name: "Native", Ext: ".native"},
+	{Name: "Annotated", Ext: ".uast"},
+	{Name: "Semantic", Ext: ".sem.uast"},
+}
+
+// findModes counts the number of fixtures of a driver for each parse mode.
+func findModes(pkg string) ([]int, error) {
+	counts := make([]int, len(ParseModes))
+	for i, m := range ParseModes {
+		files, err := findFixtures(pkg, m.Ext)
+		if err != nil {
+			return nil, err
+		}
+		counts[i] = len(files)
+	}
+	return counts, nil
+}
+
+const modesHeader = "" +
+	"# Parse modes\n\n" +
+	"The table shows the number of fixtures each driver provides " +
+	"for every parse mode.\n\n"
+
+// ModesReport renders the number of fixtures per parse mode for each language.
+func ModesReport(modes map[string][]int) string {
+	buf := bytes.NewBuffer([]byte(modesHeader))
+	buf.WriteString("Language")
+	for _, m := range ParseModes {
+		fmt.Fprintf(buf, "|%s (`%s`)", m.Name, m.Ext)
+	}
+	buf.WriteString("\n-" + strings.Repeat("|-", len(ParseModes)) + "\n")
+	for _, lang := range languages() {
+		counts, ok := modes[lang]
+		if !ok {
+			continue
+		}
+		buf.WriteString(strings.Title(lang))
+		for _, n := range counts {
+			fmt.Fprintf(buf, "|%d", n)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
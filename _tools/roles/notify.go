@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	notifyWebhook    = flag.String("notify-webhook", "", "Slack or Mattermost compatible webhook URL to post the coverage changes since the -previous report to")
+	previousFixtures = flag.String("previous-fixtures", "", "previously generated fixtures report, only the unknown roles that are not listed in it are posted to -notify-webhook")
+)
+
+// CoverageChange is a change of the number of roles used by a driver.
+type CoverageChange struct {
+	Language string
+	Old, New int
+}
+
+// findCoverageChanges compares the number of roles used by each driver with
+// the previous report. Drivers that are not in the previous report are
+// reported as changed from zero.
+func findCoverageChanges(roles Roles, prev map[string]map[string]bool) []CoverageChange {
+	old := make(map[string]int)
+	for _, langs := range prev {
+		for lang, used := range langs {
+			if used {
+				old[lang]++
+			}
+		}
+	}
+	var out []CoverageChange
+	for _, lang := range languages() {
+		n := 0
+		for _, role := range roles {
+			if role.IsUsedBy(lang) {
+				n++
+			}
+		}
+		if n != old[lang] {
+			out = append(out, CoverageChange{Language: lang, Old: old[lang], New: n})
+		}
+	}
+	return out
+}
+
+// NotifySummary renders the message about the coverage changes, regressions
+// and unknown roles found in fixtures. It returns an empty string if there is
+// nothing to report.
+func NotifySummary(changes []CoverageChange, regressions []Regression, unknown UnknownRoles) string {
+	buf := new(bytes.Buffer)
+	if len(changes) != 0 {
+		buf.WriteString("*Roles coverage changed*\n")
+		for _, c := range changes {
+			fmt.Fprintf(buf, "• %s: %d → %d roles\n", strings.Title(c.Language), c.Old, c.New)
+		}
+	}
+	if len(regressions) != 0 {
+		buf.WriteString("*Regressions*\n")
+		for _, reg := range regressions {
+			fmt.Fprintf(buf, "• %s no longer uses the `%s` role\n", strings.Title(reg.Language), reg.Role)
+		}
+	}
+	var langs []string
+	for lang, m := range unknown {
+		if len(m) != 0 {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	if len(langs) != 0 {
+		buf.WriteString("*Unknown roles in fixtures*\n")
+		for _, lang := range langs {
+			var names []string
+			for name := range unknown[lang] {
+				names = append(names, "`"+name+"`")
+			}
+			sort.Strings(names)
+			fmt.Fprintf(buf, "• %s: %s\n", strings.Title(lang), strings.Join(names, ", "))
+		}
+	}
+	return buf.String()
+}
+
+// loadPreviousUnknown reads the unknown roles listed in the fixtures report
+// by language.
+func loadPreviousUnknown(r io.Reader) (map[string]map[string]bool, error) {
+	prev := make(map[string]map[string]bool)
+	var (
+		section bool
+		lang    string
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section = line == "## Unknown roles"
+			lang = ""
+		case !section:
+		case strings.HasPrefix(line, "### "):
+			lang = strings.ToLower(strings.TrimPrefix(line, "### "))
+		case lang != "" && strings.HasPrefix(line, "- `"):
+			name := strings.TrimPrefix(line, "- `")
+			i := strings.Index(name, "`")
+			if i < 0 {
+				continue
+			}
+			m, ok := prev[lang]
+			if !ok {
+				m = make(map[string]bool)
+				prev[lang] = m
+			}
+			m[name[:i]] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return prev, nil
+}
+
+// newUnknownRoles returns the unknown roles that are not in the previous
+// fixtures report.
+func newUnknownRoles(unknown UnknownRoles, prev map[string]map[string]bool) UnknownRoles {
+	out := make(UnknownRoles)
+	for lang, m := range unknown {
+		for name, pos := range m {
+			if prev[lang][name] {
+				continue
+			}
+			if out[lang] == nil {
+				out[lang] = make(map[string][]token.Position)
+			}
+			out[lang][name] = pos
+		}
+	}
+	return out
+}
+
+// notify posts the message to the webhook in the format accepted by both
+// Slack and Mattermost.
+func notify(url, text string) error {
+	data, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook: unexpected status: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// notifyChanges compares the roles usage with the previous report and posts
+// the changes to the webhook, if there are any. Only the unknown roles that
+// are not in the -previous-fixtures report are posted, if it's set. Fixtures are analyzed on
+// a copy of the roles, thus reports that analyze them as well are not
+// affected.
+func notifyChanges(url, previousPath string, roles Roles) error {
+	if previousPath == "" {
+		return fmt.Errorf("-notify-webhook requires -previous report to compare with")
+	}
+	prev, err := loadPreviousFile(previousPath)
+	if err != nil {
+		return err
+	}
+	unknown := make(UnknownRoles)
+	fixtures := roles.clean()
+	for l, pkg := range OfficialDriver {
+		if err := findFixtureUsage(l, pkg, fixtures, unknown); err != nil {
+			return err
+		}
+	}
+	if *previousFixtures != "" {
+		f, err := os.Open(*previousFixtures)
+		if err != nil {
+			return err
+		}
+		prev, err := loadPreviousUnknown(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", *previousFixtures, err)
+		}
+		unknown = newUnknownRoles(unknown, prev)
+	}
+	text := NotifySummary(findCoverageChanges(roles, prev), findRegressions(roles, prev), unknown)
+	if text == "" {
+		return nil
+	}
+	return notify(url, text)
+}
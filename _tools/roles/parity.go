@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("parity", findParity, ParityReport))
+}
+
+// Construct is a language construct expected to be present in most languages.
+type Construct struct {
+	Name string
+	// Roles is the set of roles a node representing the construct must have.
+	Roles []string
+}
+
+// Constructs is the list of constructs checked by the parity report.
+var Constructs = []Construct{
+	{Name: "Identifier", Roles: []string{"Identifier"}},
+	{Name: "Function declaration", Roles: []string{"Function", "Declaration"}},
+	{Name: "Function call", Roles: []string{"Call"}},
+	{Name: "Type declaration", Roles: []string{"Type", "Declaration"}},
+	{Name: "Import", Roles: []string{"Import"}},
+	{Name: "Import path", Roles: []string{"Import", "Pathname"}},
+	{Name: "Import alias", Roles: []string{"Import", "Alias"}},
+	{Name: "Assignment", Roles: []string{"Assignment"}},
+	{Name: "Binary operator", Roles: []string{"Binary", "Operator"}},
+	{Name: "String literal", Roles: []string{"String", "Literal"}},
+	{Name: "Number literal", Roles: []string{"Number", "Literal"}},
+	{Name: "Boolean literal", Roles: []string{"Boolean", "Literal"}},
+	{Name: "Null literal", Roles: []string{"Null", "Literal"}},
+	{Name: "Comment", Roles: []string{"Comment"}},
+	{Name: "Documentation", Roles: []string{"Documentation"}},
+	{Name: "If statement", Roles: []string{"If"}},
+	{Name: "Switch statement", Roles: []string{"Switch"}},
+	{Name: "For loop", Roles: []string{"For"}},
+	{Name: "While loop", Roles: []string{"While"}},
+	{Name: "Try/catch", Roles: []string{"Try", "Catch"}},
+	{Name: "Return", Roles: []string{"Return"}},
+}
+
+// Matches checks if the node has all roles of the construct.
+func (c Construct) Matches(n *Node) bool {
+	for _, r := range c.Roles {
+		found := false
+		for _, nr := range n.Roles {
+			if nr == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// findParity returns the first fixture position of each construct found in
+// fixtures of a driver, indexed the same way as Constructs.
+func findParity(language, pkg string) ([]*token.Position, error) {
+	found := make([]*token.Position, len(Constructs))
+	err := walkFixtures(language, pkg, func(path string, n *Node) {
+		pos := token.Position{Filename: path, Line: n.Line}
+		for i, c := range Constructs {
+			if !c.Matches(n) {
+				continue
+			}
+			// nodes are not visited in order, thus check which one is first
+			if found[i] == nil || firstPosition([]token.Position{*found[i], pos}) == pos {
+				found[i] = &pos
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+const parityHeader = "" +
+	"# Constructs parity\n\n" +
+	"The table shows which common language constructs are represented in the " +
+	"driver fixtures. A construct is found if any node has all the listed roles.\n\n"
+
+// ParityReport renders the constructs found in fixtures of each language.
+func ParityReport(parity map[string][]*token.Position) string {
+	langs := languages()
+	buf := bytes.NewBuffer([]byte(parityHeader))
+	buf.WriteString("Construct|Roles")
+	for _, lang := range langs {
+		buf.WriteString("|" + strings.Title(lang))
+	}
+	buf.WriteString("\n-|-" + strings.Repeat("|-", len(langs)) + "\n")
+
+	for i, c := range Constructs {
+		fmt.Fprintf(buf, "%s|%s", c.Name, strings.Join(c.Roles, ", "))
+		for _, lang := range langs {
+			var used string
+			if found := parity[lang]; found != nil && found[i] != nil {
+				used = fmt.Sprintf("[✓](%s)", githubLink(lang, fixturesDir(OfficialDriver[lang]), *found[i]))
+			}
+			fmt.Fprintf(buf, "|%s", used)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
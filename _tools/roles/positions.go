@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("positions", findPositions, PositionsReport))
+}
+
+// PositionStats contains the number of nodes in the driver fixtures that
+// carry positional information.
+type PositionStats struct {
+	Files int
+	Nodes int
+	Start PositionCounts
+	End   PositionCounts
+}
+
+// PositionCounts is the number of nodes with each of the fields of a position set.
+type PositionCounts struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+func (c *PositionCounts) add(p *Position) {
+	if p == nil {
+		return
+	}
+	// offset is zero for the first token, thus the position itself is enough
+	c.Offset++
+	if p.Line > 0 {
+		c.Line++
+	}
+	if p.Col > 0 {
+		c.Col++
+	}
+}
+
+// findPositions collects the positions statistics for the fixtures of a driver.
+func findPositions(language, pkg string) (*PositionStats, error) {
+	st := &PositionStats{}
+	last := ""
+	err := walkFixtures(language, pkg, func(path string, n *Node) {
+		if path != last {
+			st.Files++
+			last = path
+		}
+		st.Nodes++
+		st.Start.add(n.StartPosition)
+		st.End.add(n.EndPosition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+const positionsHeader = "" +
+	"# Positions coverage\n\n" +
+	"The table shows the percentage of nodes in the driver fixtures " +
+	"(`*.uast` files) that carry start and end offsets, lines and columns.\n\n"
+
+// PositionsReport renders the positions coverage of the fixtures for each language.
+func PositionsReport(stats map[string]*PositionStats) string {
+	buf := bytes.NewBuffer([]byte(positionsHeader))
+	buf.WriteString("Language|Files|Nodes|Start offset|Start line|Start col|End offset|End line|End col\n")
+	buf.WriteString("-|-|-|-|-|-|-|-|-\n")
+	for _, lang := range languages() {
+		st, ok := stats[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "%s|%d|%d", strings.Title(lang), st.Files, st.Nodes)
+		for _, c := range []PositionCounts{st.Start, st.End} {
+			fmt.Fprintf(buf, "|%s|%s|%s",
+				percent(c.Offset, st.Nodes),
+				percent(c.Line, st.Nodes),
+				percent(c.Col, st.Nodes),
+			)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+var (
+	cpuProfile = flag.String("cpuprofile", "", "write the CPU profile to a file")
+	memProfile = flag.String("memprofile", "", "write the heap profile to a file on exit")
+	traceFile  = flag.String("trace", "", "write the execution trace to a file")
+)
+
+// startProfiles starts the CPU profiling and the execution tracing if
+// requested. The returned function stops them and writes the heap profile,
+// and must be called before exiting.
+func startProfiles() func() {
+	var tr *os.File
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := trace.Start(f); err != nil {
+			log.Fatal(err)
+		}
+		tr = f
+	}
+	var cpu *os.File
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal(err)
+		}
+		cpu = f
+	}
+	return func() {
+		if tr != nil {
+			trace.Stop()
+			if err := tr.Close(); err != nil {
+				log.Printf("cannot write the execution trace: %v", err)
+			}
+		}
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				log.Printf("cannot write the CPU profile: %v", err)
+			}
+		}
+		if *memProfile != "" {
+			if err := writeHeapProfile(*memProfile); err != nil {
+				log.Printf("cannot write the heap profile: %v", err)
+			}
+		}
+	}
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// collect the garbage to get the up-to-date statistics
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// region marks a stage of the run in the execution trace, e.g. a clone or
+// decoding of a fixture. The returned function ends the region.
+func region(name string) func() {
+	return trace.StartRegion(context.Background(), name).End
+}
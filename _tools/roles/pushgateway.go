@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	pushgateway = flag.String("pushgateway", "", "URL of the Prometheus Pushgateway to push the metrics of the run to")
+	pushJob     = flag.String("push-job", "roles", "job name of the metrics pushed to the Pushgateway")
+)
+
+// metrics are the metrics of the current run.
+var metrics = &runMetrics{}
+
+// runMetrics are the metrics of a single run pushed to the Pushgateway.
+type runMetrics struct {
+	Start time.Time
+	// LoadFailures is the number of drivers that failed to load.
+	LoadFailures int
+	// FixtureFailures is the number of fixture files that failed to parse.
+	// It's updated atomically, since fixtures are decoded in parallel.
+	FixtureFailures int64
+	// Repaired is the number of corrupted clones that were cloned again.
+	Repaired int
+}
+
+// write writes the metrics of the run in the Prometheus text format.
+func (m *runMetrics) write(w io.Writer, r Roles) {
+	// roles are not found if the run failed early
+	if len(r) != 0 {
+		m.writeCoverage(w, r)
+	}
+	fmt.Fprintf(w, "# HELP roles_load_failures Number of drivers that failed to load.\n")
+	fmt.Fprintf(w, "# TYPE roles_load_failures gauge\n")
+	fmt.Fprintf(w, "roles_load_failures %d\n", m.LoadFailures)
+	fmt.Fprintf(w, "# HELP roles_fixture_failures Number of fixture files that failed to parse.\n")
+	fmt.Fprintf(w, "# TYPE roles_fixture_failures gauge\n")
+	fmt.Fprintf(w, "roles_fixture_failures %d\n", atomic.LoadInt64(&m.FixtureFailures))
+	fmt.Fprintf(w, "# HELP roles_repaired_clones Number of corrupted driver clones that were cloned again.\n")
+	fmt.Fprintf(w, "# TYPE roles_repaired_clones gauge\n")
+	fmt.Fprintf(w, "roles_repaired_clones %d\n", m.Repaired)
+	fmt.Fprintf(w, "# HELP roles_run_duration_seconds Duration of the run.\n")
+	fmt.Fprintf(w, "# TYPE roles_run_duration_seconds gauge\n")
+	fmt.Fprintf(w, "roles_run_duration_seconds %.3f\n", time.Since(m.Start).Seconds())
+	fmt.Fprintf(w, "# HELP roles_last_run_timestamp_seconds Unix time of the end of the run.\n")
+	fmt.Fprintf(w, "# TYPE roles_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(w, "roles_last_run_timestamp_seconds %d\n", time.Now().Unix())
+}
+
+// writeCoverage writes the coverage metrics of each language.
+func (m *runMetrics) writeCoverage(w io.Writer, r Roles) {
+	fmt.Fprintf(w, "# HELP roles_coverage_score Weighted share of roles used by the driver annotations, in percent.\n")
+	fmt.Fprintf(w, "# TYPE roles_coverage_score gauge\n")
+	for _, lang := range languages() {
+		fmt.Fprintf(w, "roles_coverage_score{language=%q} %.2f\n", lang, r.Score(lang))
+	}
+	fmt.Fprintf(w, "# HELP roles_annotated Number of roles used by the driver annotations.\n")
+	fmt.Fprintf(w, "# TYPE roles_annotated gauge\n")
+	for _, lang := range languages() {
+		n := 0
+		for _, role := range r {
+			if role.IsUsedBy(lang) {
+				n++
+			}
+		}
+		fmt.Fprintf(w, "roles_annotated{language=%q} %d\n", lang, n)
+	}
+}
+
+// push replaces the metrics of the job in the Pushgateway with the metrics
+// of this run.
+func (m *runMetrics) push(addr, job string, r Roles) error {
+	buf := bytes.NewBuffer(nil)
+	m.write(buf, r)
+	u := strings.TrimSuffix(addr, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequest("PUT", u, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway: unexpected status: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
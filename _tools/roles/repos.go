@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	extraRepos stringList
+	mirrors    stringList
+	reposDir   = flag.String("repos-dir", defaultReposDir(), "directory to clone the driver repositories specified with -repo to (defaults to $"+ReposDirEnv+" if set)")
+
+	gitProxy     = flag.String("proxy", "", "URL of the HTTP proxy for cloning the driver repositories, instead of the one set by HTTPS_PROXY and HTTP_PROXY environment variables")
+	cloneTimeout = flag.Duration("clone-timeout", 10*time.Minute, "timeout for cloning a driver repository")
+	reposBudget  = flag.Int64("repos-budget", 0, "warn if the driver clones occupy more disk space than this, in MB (0 is unlimited)")
+	fetchTimeout = flag.Duration("fetch-timeout", 5*time.Minute, "timeout for fetching updates of a cloned driver repository")
+)
+
+// ReposDirEnv is the environment variable with the directory to clone the
+// driver repositories to. It allows CI to keep the clones on a persistent
+// volume shared across jobs.
+const ReposDirEnv = "BBLFSH_REPOS_DIR"
+
+func defaultReposDir() string {
+	if dir := os.Getenv(ReposDirEnv); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "bblfsh-drivers")
+}
+
+func init() {
+	flag.Var(&extraRepos, "repo", "include the driver repository with a given URL@ref in the report (can be repeated)")
+	flag.Var(&mirrors, "mirror", "clone the driver repository from a mirror or over SSH, as language=URL, e.g. python=git@ghe.example.com:mirrors/python-driver.git (can be repeated)")
+}
+
+// remoteURL returns the URL to clone the repository from. It's the URL of the
+// mirror if it's specified for the driver, or the URL itself.
+func remoteURL(url string) string {
+	_, name := repoName(url)
+	lang := strings.TrimSuffix(name, "-driver")
+	for _, m := range mirrors {
+		if i := strings.Index(m, "="); i > 0 && m[:i] == lang {
+			return m[i+1:]
+		}
+	}
+	return url
+}
+
+// splitRepo splits the URL@ref into the repository URL and the Git ref.
+// The ref is empty if the URL has no ref suffix. Only the @ in the path of
+// the repository separates the ref, thus the user part of the URL, e.g. in
+// ssh://git@github.com/org/repo or git@github.com:org/repo, is kept.
+func splitRepo(s string) (url, ref string) {
+	start := 0
+	if i := strings.Index(s, "://"); i >= 0 {
+		j := strings.Index(s[i+3:], "/")
+		if j < 0 {
+			return s, ""
+		}
+		start = i + 3 + j
+	} else if i := strings.Index(s, ":"); i > 0 && !strings.ContainsAny(s[:i], `/\`) {
+		// SCP-like syntax, e.g. git@github.com:org/repo
+		start = i + 1
+	}
+	i := strings.LastIndex(s[start:], "@")
+	if i < 0 {
+		return s, ""
+	}
+	i += start
+	return s[:i], s[i+1:]
+}
+
+// repoName returns the owner and the name of the repository given its URL.
+// The URL may also be a local path with OS-specific separators.
+func repoName(url string) (owner, name string) {
+	url = filepath.ToSlash(url)
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	url = strings.Replace(url, ":", "/", -1)
+	name = path.Base(url)
+	owner = path.Base(path.Dir(url))
+	return owner, name
+}
+
+// reposRoot is a directory with clones of the driver repositories.
+type reposRoot string
+
+// dir returns the directory of the worktree of the repository with a given URL.
+func (r reposRoot) dir(url string) string {
+	owner, name := repoName(url)
+	return filepath.Join(string(r), sanitizeName(owner), sanitizeName(name))
+}
+
+// bare returns the directory of the bare clone of the repository with a given
+// URL. Worktrees of the clone share its object database.
+func (r reposRoot) bare(url string) string {
+	return r.dir(url) + ".git"
+}
+
+// sanitizeName replaces characters that are not allowed in file names on
+// some of the platforms.
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	// Windows does not allow names ending with a dot, and "." and ".."
+	// are special everywhere
+	name = strings.TrimRight(name, ".")
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// cloneRepo clones the repository or updates the existing clone, and checks
+// out a given ref. It returns the directory of the worktree. The clone is
+// named after the repository URL, even if it's cloned from a mirror.
+//
+// The repository is stored as a bare blobless clone, thus only the files of
+// the checked out revisions are downloaded and stored, and the worktree is
+// the only copy of the files on disk.
+func cloneRepo(url, ref string) (string, error) {
+	root := reposRoot(*reposDir)
+	bare, err := fetchRef(url, ref)
+	if err != nil {
+		return "", err
+	}
+	dir := root.dir(url)
+	if err := checkoutFetched(bare, dir); err != nil {
+		return "", err
+	}
+	return localDriver(dir)
+}
+
+// fetchRef clones the bare repository or updates the existing clone, and
+// fetches a given ref into its FETCH_HEAD. It returns the directory of the
+// bare clone.
+func fetchRef(url, ref string) (string, error) {
+	bare := reposRoot(*reposDir).bare(url)
+	remote := remoteURL(url)
+	if _, err := os.Stat(bare); os.IsNotExist(err) {
+		if err := git(*cloneTimeout, "", "clone", "--quiet", "--bare", "--filter=blob:none", remote, bare); err != nil {
+			// partial clone would be mistaken for a complete one
+			os.RemoveAll(bare)
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	} else if err := git(*fetchTimeout, bare, "remote", "set-url", "origin", remote); err != nil {
+		// mirror may be changed between runs
+		return "", err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := git(*fetchTimeout, bare, "fetch", "--quiet", "origin", ref); err != nil {
+		return "", err
+	}
+	return bare, nil
+}
+
+// checkoutFetched checks out the FETCH_HEAD of the bare clone to the worktree
+// in a given directory, adding the worktree if it doesn't exist.
+func checkoutFetched(bare, dir string) error {
+	if old, err := isFullClone(dir); err != nil {
+		return err
+	} else if old {
+		// the worktree is added in place of the clone, the files are
+		// downloaded again from the bare clone's remote
+		log.Printf("%s: replacing the clone with a worktree of %s", dir, bare)
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		// drop the worktrees that were removed manually
+		if err := git(*fetchTimeout, bare, "worktree", "prune"); err != nil {
+			return err
+		}
+		// the command runs in the bare clone, thus a relative path, e.g.
+		// with a relative -repos-dir, would be resolved against it
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		return git(*fetchTimeout, bare, "worktree", "add", "--quiet", "--detach", abs, "FETCH_HEAD")
+	} else if err != nil {
+		return err
+	}
+	rev, err := gitOutput(bare, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return err
+	}
+	// blobs of the revision are downloaded from the bare clone's remote
+	return git(*fetchTimeout, dir, "checkout", "--quiet", "--detach", rev)
+}
+
+// isFullClone checks if the directory is a complete clone with its own .git
+// directory, as made before the clones were stored as bare repositories. The
+// worktrees of bare clones have a .git file instead.
+func isFullClone(dir string) (bool, error) {
+	fi, err := os.Stat(filepath.Join(dir, ".git"))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return fi.IsDir(), nil
+}
+
+// git runs the Git command in a given directory. The command is killed if it
+// doesn't finish in time, e.g. because of a hung connection.
+func git(timeout time.Duration, dir string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	// never wait for the credentials to be entered
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if *gitProxy != "" {
+		cmd.Env = append(cmd.Env, "http_proxy="+*gitProxy, "https_proxy="+*gitProxy)
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return &timeoutError{cmd: strings.Join(args, " "), timeout: timeout}
+		}
+		return fmt.Errorf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// timeoutError is returned when the Git command didn't finish in time. Those
+// are caused by the network, not by the state of the clone.
+type timeoutError struct {
+	cmd     string
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("git %s: timed out after %v", e.cmd, e.timeout)
+}
+
+// gitOutput runs the Git command in a given directory and returns its output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// addRepos clones the additional driver repositories and adds them to the
+// list of drivers. A fork of an already listed driver is named by the owner
+// of the repository, e.g. python (user). It returns the repositories whose
+// clones were corrupted and cloned again.
+func addRepos(repos []string) ([]string, error) {
+	var repaired []string
+	for _, s := range repos {
+		url, ref := splitRepo(s)
+		owner, name := repoName(url)
+		lang := strings.TrimSuffix(name, "-driver")
+		if excluded(lang) {
+			log.Printf("%s: driver is excluded", s)
+			continue
+		}
+		end, stop := region("clone"), timings.track(PhaseFetch, lang)
+		dir, ok, err := repairRepo(url, ref)
+		end()
+		stop()
+		if err != nil {
+			return repaired, err
+		} else if ok {
+			repaired = append(repaired, url)
+		}
+		if _, ok := OfficialDriver[lang]; ok {
+			lang = fmt.Sprintf("%s (%s)", lang, owner)
+		}
+		if _, ok := OfficialDriver[lang]; ok {
+			return repaired, fmt.Errorf("%s: driver for %s is specified multiple times", s, lang)
+		}
+		OfficialDriver[lang] = dir
+	}
+	if len(repaired) != 0 {
+		log.Printf("warning: %d corrupted clones were removed and cloned again: %s",
+			len(repaired), strings.Join(repaired, ", "))
+	}
+	return repaired, reportDiskUsage(repos)
+}
+
+// repairRepo is like cloneRepo, but if the existing clone is corrupted, it's
+// removed and the repository is cloned again. Other failures, e.g. network or
+// authentication errors, are returned as is, since cloning again won't fix
+// them. It reports if the clone was repaired this way.
+func repairRepo(url, ref string) (string, bool, error) {
+	dir, err := cloneRepo(url, ref)
+	if err == nil {
+		return dir, false, nil
+	}
+	root := reposRoot(*reposDir)
+	bare := root.bare(url)
+	if _, ok := err.(*timeoutError); ok {
+		return "", false, err
+	} else if _, serr := os.Stat(bare); serr != nil {
+		// the clone itself failed
+		return "", false, err
+	}
+	cerr := checkClone(bare, root.dir(url))
+	if cerr == nil {
+		// the clone is intact, e.g. the remote is not reachable
+		return "", false, err
+	}
+	log.Printf("%s: %v: %v; cloning again", url, err, cerr)
+	if err := removeClone(bare, root.dir(url)); err != nil {
+		return "", false, err
+	}
+	dir, err = cloneRepo(url, ref)
+	if err != nil {
+		return "", false, err
+	}
+	return dir, true, nil
+}
+
+// checkClone checks the bare clone and its worktree, if any, for corruption.
+// The objects missing from the blobless clone are not reported.
+func checkClone(bare, dir string) error {
+	if _, err := gitOutput(bare, "rev-parse", "--verify", "HEAD"); err != nil {
+		return err
+	}
+	if err := git(*fetchTimeout, bare, "fsck", "--connectivity-only", "--no-progress"); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := gitOutput(dir, "rev-parse", "--verify", "HEAD"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// reportDiskUsage logs the disk space occupied by the clone of each of the
+// repositories and by all of them, and warns if the total exceeds the budget.
+func reportDiskUsage(repos []string) error {
+	root := reposRoot(*reposDir)
+	var total int64
+	for _, s := range repos {
+		url, _ := splitRepo(s)
+		var size int64
+		for _, dir := range []string{root.bare(url), root.dir(url)} {
+			n, err := dirSize(dir)
+			if os.IsNotExist(err) {
+				// excluded driver
+				continue
+			} else if err != nil {
+				return err
+			}
+			size += n
+		}
+		log.Printf("%s: %s on disk", url, formatSize(size))
+		total += size
+	}
+	log.Printf("driver clones: %s on disk in total", formatSize(total))
+	if budget := *reposBudget << 20; budget > 0 && total > budget {
+		log.Printf("warning: driver clones exceed the budget of %s", formatSize(budget))
+	}
+	return nil
+}
+
+// dirSize returns the total size of files in the directory.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(_ string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func formatSize(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestSplitRepo(t *testing.T) {
+	cases := []struct {
+		in       string
+		url, ref string
+	}{
+		{in: "https://github.com/bblfsh/python-driver", url: "https://github.com/bblfsh/python-driver"},
+		{in: "https://github.com/bblfsh/python-driver@v2.9.0", url: "https://github.com/bblfsh/python-driver", ref: "v2.9.0"},
+		{in: "https://github.com/bblfsh/python-driver@feature/positions", url: "https://github.com/bblfsh/python-driver", ref: "feature/positions"},
+		{in: "https://user@github.com/bblfsh/python-driver", url: "https://user@github.com/bblfsh/python-driver"},
+		{in: "https://user@github.com/bblfsh/python-driver@master", url: "https://user@github.com/bblfsh/python-driver", ref: "master"},
+		{in: "ssh://git@github.com/bblfsh/python-driver.git", url: "ssh://git@github.com/bblfsh/python-driver.git"},
+		{in: "ssh://git@github.com/bblfsh/python-driver.git@v2", url: "ssh://git@github.com/bblfsh/python-driver.git", ref: "v2"},
+		{in: "ssh://git@github.com", url: "ssh://git@github.com"},
+		{in: "git@github.com:bblfsh/python-driver.git", url: "git@github.com:bblfsh/python-driver.git"},
+		{in: "git@github.com:bblfsh/python-driver.git@v2", url: "git@github.com:bblfsh/python-driver.git", ref: "v2"},
+		{in: "../python-driver", url: "../python-driver"},
+		{in: "../python-driver@HEAD~1", url: "../python-driver", ref: "HEAD~1"},
+		{in: `C:\src\python-driver@v2`, url: `C:\src\python-driver`, ref: "v2"},
+	}
+	for _, tc := range cases {
+		url, ref := splitRepo(tc.in)
+		if url != tc.url || ref != tc.ref {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", tc.in, url, ref, tc.url, tc.ref)
+		}
+	}
+}
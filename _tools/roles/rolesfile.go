@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"strings"
+)
+
+var (
+	rolesFile = flag.String("roles-file", "", "file with the list of roles to report, including the ones not yet defined in the SDK")
+)
+
+// loadRolesFile reads the list of roles to report. Each line contains the name
+// of the role, optionally followed by its description. Empty lines and lines
+// starting with # are ignored:
+//
+//	Identifier
+//	Lambda  Anonymous function, proposed for the next SDK version.
+//
+// Roles defined in the SDK keep their documentation if the file has none.
+func loadRolesFile(path string, sdk Roles) (Roles, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	known := make(map[string]*Role, len(sdk))
+	for _, r := range sdk {
+		known[r.Name] = r
+	}
+	var (
+		out  Roles
+		seen = make(map[string]bool)
+		line int
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		name, doc := s, ""
+		if i := strings.IndexAny(s, " \t"); i >= 0 {
+			name, doc = s[:i], strings.TrimSpace(s[i+1:])
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s:%d: duplicate role %s", path, line, name)
+		}
+		seen[name] = true
+		if r, ok := known[name]; ok && doc == "" {
+			doc = r.Doc
+		} else if !ok {
+			doc = strings.TrimSpace(doc + "\n\nNot defined in the SDK yet.")
+		}
+		out = append(out, &Role{
+			Name:      name,
+			Doc:       doc,
+			Languages: make(map[string][]token.Position),
+			Fixtures:  make(map[string][]token.Position),
+			Types:     make(map[string]map[string]int),
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
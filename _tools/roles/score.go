@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+var (
+	minCoverage = flag.Float64("min-coverage", 0, "exit with a non-zero code if the coverage score of any driver is below this percentage")
+	weightsFile = flag.String("weights", "", "JSON file with the weight of each role for the coverage score, e.g. {\"Identifier\": 5} (overrides the defaults)")
+)
+
+// DefaultWeight is the weight of roles that are not listed in the weights.
+const DefaultWeight = 1.0
+
+// RoleWeights are the default weights of the roles in the coverage score.
+// Roles that almost every program uses weigh more than the specific ones.
+var RoleWeights = map[string]float64{
+	"Identifier":  3,
+	"Import":      3,
+	"Function":    3,
+	"Declaration": 3,
+	"Call":        3,
+	"Assignment":  3,
+	"Literal":     3,
+	"If":          3,
+	"For":         3,
+	"While":       3,
+	"Return":      3,
+	"Type":        3,
+	"File":        3,
+	"Block":       3,
+	"Comment":     3,
+
+	"Whitespace": 0.5,
+	"Noop":       0.5,
+	"Incomplete": 0.5,
+	"Invalid":    0.5,
+	"Friend":     0.5,
+	"Goto":       0.5,
+
+	// not an actual construct, but a mark for the nodes without roles
+	UnannotatedRole: 0,
+}
+
+// loadWeights reads the weights of the roles from a JSON file and merges them
+// with the defaults.
+func loadWeights(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var m map[string]float64
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	for name, w := range m {
+		if w < 0 {
+			return fmt.Errorf("%s: negative weight of %s", path, name)
+		}
+		RoleWeights[name] = w
+	}
+	return nil
+}
+
+func roleWeight(name string) float64 {
+	if w, ok := RoleWeights[name]; ok {
+		return w
+	}
+	return DefaultWeight
+}
+
+// Score returns the weighted share of the roles used by the annotation rules
+// of the language, in percent.
+func (r Roles) Score(lang string) float64 {
+	var used, total float64
+	for _, role := range r {
+		w := roleWeight(role.Name)
+		total += w
+		if role.IsUsedBy(lang) {
+			used += w
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * used / total
+}
+
+// writeScores writes the coverage score of each language.
+func writeScores(w *bytes.Buffer, r Roles, langs []string) {
+	w.WriteString("## Coverage score\n\n" +
+		"The weighted share of roles used by each driver. Fundamental roles " +
+		"such as `Identifier` or `Import` weigh more than the specific ones.\n\n")
+	w.WriteString("Language|Score\n-|-\n")
+	for _, lang := range langs {
+		fmt.Fprintf(w, "%s|%.1f%%\n", strings.Title(lang), r.Score(lang))
+	}
+	w.WriteString("\n")
+}
+
+// checkCoverage reports drivers with the coverage score below the minimum.
+// It returns false if there are any.
+func checkCoverage(r Roles, min float64) bool {
+	ok := true
+	for _, lang := range languages() {
+		if score := r.Score(lang); score < min {
+			log.Printf("%s: coverage score %.1f%% is below %.1f%%", lang, score, min)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// errNotFlow is returned by scanSemantic for documents that are not in the
+// flow style, thus cannot be checked by it.
+var errNotFlow = errors.New("not a flow style document")
+
+// scanSemantic checks the syntax of a semantic UAST fixture one token at
+// a time, thus the size of the file doesn't matter. Only the flow style
+// used by the SDK to write the fixtures is supported, i.e. the document is
+// a single mapping with nested mappings and sequences, and errNotFlow is
+// returned for other documents.
+func scanSemantic(r io.Reader) error {
+	s := &flowScanner{r: bufio.NewReader(r), line: 1}
+	tok, err := s.next()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case tokEOF:
+		return fmt.Errorf("empty document")
+	case '{':
+	default:
+		return errNotFlow
+	}
+	hasType, err := s.mapping()
+	if err != nil {
+		return err
+	}
+	if !hasType {
+		return fmt.Errorf("the root node has no @type")
+	}
+	if tok, err := s.next(); err != nil {
+		return err
+	} else if tok != tokEOF {
+		return fmt.Errorf("line %d: unexpected data after the root node", s.line)
+	}
+	return nil
+}
+
+const (
+	tokEOF    = 0
+	tokScalar = 's'
+)
+
+// flowScanner splits a YAML document in the flow style into tokens: flow
+// indicators and scalars.
+type flowScanner struct {
+	r    *bufio.Reader
+	line int
+	// text is the value of the last scalar
+	text string
+	// wasQuoted is set if the last token is a quoted scalar, which may be
+	// followed by a colon without a space in between, as in JSON
+	wasQuoted bool
+}
+
+// next returns the next token, that is either one of the indicators ({}[],:),
+// a scalar, or tokEOF at the end of the document.
+func (s *flowScanner) next() (byte, error) {
+	afterQuoted := s.wasQuoted
+	s.wasQuoted = false
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return tokEOF, nil
+		} else if err != nil {
+			return 0, err
+		}
+		switch c {
+		case '\n':
+			s.line++
+		case ' ', '\t', '\r':
+		case '#':
+			if _, err := s.r.ReadString('\n'); err == io.EOF {
+				return tokEOF, nil
+			} else if err != nil {
+				return 0, err
+			}
+			s.line++
+		case '{', '}', '[', ']', ',':
+			return c, nil
+		case ':':
+			if afterQuoted || s.indicator() {
+				return c, nil
+			}
+			return tokScalar, s.plain(c)
+		case '\'', '"':
+			return tokScalar, s.quoted(c)
+		default:
+			return tokScalar, s.plain(c)
+		}
+	}
+}
+
+// indicator checks if the colon that was just read separates a key from the
+// value, i.e. it's followed by a space or the end of the value.
+func (s *flowScanner) indicator() bool {
+	b, err := s.r.Peek(1)
+	if err != nil {
+		return true
+	}
+	return strings.IndexByte(" \t\r\n,[]{}", b[0]) >= 0
+}
+
+// quoted reads a quoted scalar. Single-quoted scalars escape the quote by
+// repeating it, while double-quoted ones use backslash escapes.
+func (s *flowScanner) quoted(q byte) error {
+	start := s.line
+	var buf strings.Builder
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return fmt.Errorf("line %d: unterminated string", start)
+		} else if err != nil {
+			return err
+		}
+		switch {
+		case c == '\n':
+			s.line++
+		case c == '\\' && q == '"':
+			if c, err = s.r.ReadByte(); err == io.EOF {
+				return fmt.Errorf("line %d: unterminated string", start)
+			} else if err != nil {
+				return err
+			}
+			if c == '\n' {
+				s.line++
+			}
+		case c == q:
+			if b, err := s.r.Peek(1); q == '\'' && err == nil && b[0] == q {
+				s.r.ReadByte()
+			} else {
+				s.text, s.wasQuoted = buf.String(), true
+				return nil
+			}
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// plain reads a plain scalar that starts with a given character. It ends
+// before an indicator or a comment, and may span multiple lines.
+func (s *flowScanner) plain(first byte) error {
+	var buf strings.Builder
+	buf.WriteByte(first)
+	for {
+		b, err := s.r.Peek(2)
+		if len(b) == 0 {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		c := b[0]
+		if strings.IndexByte(",[]{}", c) >= 0 ||
+			c == ':' && (len(b) < 2 || strings.IndexByte(" \t\r\n,[]{}", b[1]) >= 0) ||
+			c == '#' && strings.IndexByte(" \t\r\n", lastByte(&buf)) >= 0 {
+			break
+		}
+		s.r.ReadByte()
+		if c == '\n' {
+			s.line++
+		}
+		buf.WriteByte(c)
+	}
+	s.text = strings.TrimSpace(buf.String())
+	return nil
+}
+
+func lastByte(buf *strings.Builder) byte {
+	str := buf.String()
+	return str[len(str)-1]
+}
+
+// value checks a value that starts with a given token.
+func (s *flowScanner) value(tok byte) error {
+	switch tok {
+	case '{':
+		_, err := s.mapping()
+		return err
+	case '[':
+		return s.sequence()
+	case tokScalar:
+		return nil
+	case tokEOF:
+		return fmt.Errorf("line %d: unexpected end of the document", s.line)
+	}
+	return fmt.Errorf("line %d: unexpected '%c'", s.line, tok)
+}
+
+// mapping checks the entries of a mapping after the opening brace. It reports
+// if one of the keys is @type, i.e. the mapping is a node.
+func (s *flowScanner) mapping() (bool, error) {
+	hasType := false
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return false, err
+		}
+		switch tok {
+		case '}':
+			return hasType, nil
+		case tokScalar:
+			hasType = hasType || s.text == "@type"
+		default:
+			return false, fmt.Errorf("line %d: expected a key, got %s", s.line, tokName(tok))
+		}
+		if tok, err = s.next(); err != nil {
+			return false, err
+		} else if tok != ':' {
+			return false, fmt.Errorf("line %d: expected ':' after the key, got %s", s.line, tokName(tok))
+		}
+		if tok, err = s.next(); err != nil {
+			return false, err
+		}
+		// the value may be omitted
+		if tok != ',' && tok != '}' {
+			if err := s.value(tok); err != nil {
+				return false, err
+			}
+			if tok, err = s.next(); err != nil {
+				return false, err
+			}
+		}
+		switch tok {
+		case ',':
+		case '}':
+			return hasType, nil
+		default:
+			return false, fmt.Errorf("line %d: expected ',' or '}', got %s", s.line, tokName(tok))
+		}
+	}
+}
+
+// sequence checks the items of a sequence after the opening bracket.
+func (s *flowScanner) sequence() error {
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return err
+		}
+		if tok == ']' {
+			return nil
+		}
+		if err := s.value(tok); err != nil {
+			return err
+		}
+		if tok, err = s.next(); err != nil {
+			return err
+		}
+		switch tok {
+		case ',':
+		case ']':
+			return nil
+		default:
+			return fmt.Errorf("line %d: expected ',' or ']', got %s", s.line, tokName(tok))
+		}
+	}
+}
+
+func tokName(tok byte) string {
+	switch tok {
+	case tokEOF:
+		return "end of the document"
+	case tokScalar:
+		return "a scalar"
+	}
+	return fmt.Sprintf("'%c'", tok)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	serveAddr = flag.String("addr", ":8080", "address to listen on in serve mode")
+)
+
+// reportServer keeps the analysis results in memory and serves the reports.
+type reportServer struct {
+	mu      sync.RWMutex
+	roles   Roles
+	updated time.Time
+
+	// refresh is held while the analysis is running
+	refresh sync.Mutex
+}
+
+// runServe analyzes the drivers and serves the reports over HTTP until the
+// process is stopped. The analysis is run again on POST to /refresh, using
+// the drivers as they are checked out, i.e. clones are not updated.
+func runServe() error {
+	s := &reportServer{}
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleReport("/", "text/html; charset=utf-8", func(r Roles, w *bytes.Buffer) error {
+		html, err := r.Heatmap()
+		w.WriteString(html)
+		return err
+	}))
+	mux.HandleFunc("/roles.md", s.handleReport("/roles.md", "text/markdown; charset=utf-8", func(r Roles, w *bytes.Buffer) error {
+		w.WriteString(r.String())
+		return nil
+	}))
+	mux.HandleFunc("/roles.json", s.handleReport("/roles.json", "application/json", func(r Roles, w *bytes.Buffer) error {
+		return writeCoverage(w, r.Coverage())
+	}))
+	mux.HandleFunc("/refresh", s.handleRefresh)
+
+	log.Println("listening on", *serveAddr)
+	return http.ListenAndServe(*serveAddr, withRequestID(mux))
+}
+
+// analyze finds the roles used by the annotations and the fixtures of all
+// drivers. Drivers that cannot be loaded are skipped with a warning, as for
+// the reports.
+func analyze() (Roles, error) {
+	roles, err := findRoles()
+	if err != nil {
+		return nil, err
+	}
+	unknown := make(UnknownRoles)
+	for _, l := range languages() {
+		pkg := OfficialDriver[l]
+		if err := findUsage(l, pkg, roles); err != nil {
+			log.Printf("warning: cannot load %s driver, its roles won't be reported: %v", l, err)
+		}
+		if err := findFixtureUsage(l, pkg, roles, unknown); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+func (s *reportServer) reload() error {
+	s.refresh.Lock()
+	defer s.refresh.Unlock()
+
+	roles, err := analyze()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.roles, s.updated = roles, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *reportServer) handleReport(path, typ string, fn func(r Roles, w *bytes.Buffer) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the root handler matches all paths
+		if r.URL.Path != path {
+			writeError(w, r, http.StatusNotFound, fmt.Errorf("no report at %s", r.URL.Path))
+			return
+		}
+		s.mu.RLock()
+		roles, updated := s.roles, s.updated
+		s.mu.RUnlock()
+
+		// render to a buffer first to be able to report an error
+		buf := new(bytes.Buffer)
+		if err := fn(roles, buf); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", typ)
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+		w.Write(buf.Bytes())
+	}
+}
+
+func (s *reportServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, http.StatusMethodNotAllowed, errors.New("use POST to refresh the report"))
+		return
+	}
+	if err := s.reload(); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// requestIDHeader is the header with the ID of the request. A valid ID set
+// by the client or a proxy is kept, thus the logs can be correlated with theirs.
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// withRequestID assigns an ID to each request, returns it in the response
+// header and logs the request with it.
+func withRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+		start := time.Now()
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		log.Printf("[%s] %s %s (%v)", id, r.Method, r.URL.Path, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// validRequestID checks that the ID is short and only has the characters
+// safe to write to the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the ID of the request, see withRequestID.
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// errorBody is the body of the error responses.
+type errorBody struct {
+	Status    int    `json:"status"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id"`
+}
+
+// writeError logs the error with the request ID and writes it as JSON.
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	id := requestID(r)
+	log.Printf("[%s] %s %s: %v", id, r.Method, r.URL.Path, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorBody{Status: status, Error: err.Error(), RequestID: id})
+}
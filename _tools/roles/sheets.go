@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	sheetsAPI   = "https://sheets.googleapis.com/v4/spreadsheets/"
+	sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+var (
+	sheetID    = flag.String("sheet", "", "ID of the Google Sheet to export the coverage matrix to with 'export sheets'")
+	sheetRange = flag.String("sheet-range", "Coverage", "sheet (tab) name or A1 range to write the coverage matrix to; its content is replaced")
+	sheetCreds = flag.String("sheet-credentials", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"), "service account key file for the Google Sheets export")
+)
+
+// serviceAccount is the part of the Google service account key file needed
+// to authenticate.
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// coverageMatrix returns the roles usage as rows of a spreadsheet: the number
+// of usages in the annotations and the fixtures of each language.
+func coverageMatrix(r Roles) [][]interface{} {
+	langs := languages()
+	header := []interface{}{"Role"}
+	for _, lang := range langs {
+		header = append(header, strings.Title(lang)+" annotations", strings.Title(lang)+" fixtures")
+	}
+	rows := [][]interface{}{header}
+	for _, role := range r {
+		row := []interface{}{role.Name}
+		for _, lang := range langs {
+			row = append(row, len(role.Languages[lang]), len(role.Fixtures[lang]))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// exportSheet replaces the content of the Google Sheet range with the roles
+// coverage matrix. The sheet must be shared with the service account.
+func exportSheet(w io.Writer, r Roles) error {
+	if *sheetID == "" || *sheetCreds == "" {
+		return fmt.Errorf("both -sheet and -sheet-credentials (or GOOGLE_APPLICATION_CREDENTIALS) must be set")
+	}
+	for l, pkg := range OfficialDriver {
+		if err := findFixtureUsage(l, pkg, r, make(UnknownRoles)); err != nil {
+			return err
+		}
+	}
+	acc, err := loadServiceAccount(*sheetCreds)
+	if err != nil {
+		return err
+	}
+	token, err := acc.accessToken()
+	if err != nil {
+		return err
+	}
+	base := sheetsAPI + url.PathEscape(*sheetID) + "/values/" + url.PathEscape(*sheetRange)
+
+	// rows of the removed roles must not be left behind
+	if err := sheetsRequest(token, "POST", base+":clear", struct{}{}); err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"range":          *sheetRange,
+		"majorDimension": "ROWS",
+		"values":         coverageMatrix(r),
+	}
+	if err := sheetsRequest(token, "PUT", base+"?valueInputOption=RAW", body); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "https://docs.google.com/spreadsheets/d/%s\n", *sheetID)
+	return nil
+}
+
+func sheetsRequest(token, method, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sheets: unexpected status: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func loadServiceAccount(path string) (*serviceAccount, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var acc serviceAccount
+	if err := json.Unmarshal(data, &acc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if acc.ClientEmail == "" || acc.PrivateKey == "" {
+		return nil, fmt.Errorf("%s: not a service account key file", path)
+	}
+	if acc.TokenURI == "" {
+		acc.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &acc, nil
+}
+
+// accessToken exchanges a JWT signed with the service account key for an
+// OAuth2 access token, as described in Google's service account guide.
+func (acc *serviceAccount) accessToken() (string, error) {
+	block, _ := pem.Decode([]byte(acc.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("cannot decode the service account private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account private key is not an RSA key")
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   acc.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   acc.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.PostForm(acc.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + enc.EncodeToString(sig)},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot get the access token: %s", resp.Status)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxShowFiles is the number of fixture files listed for each role.
+const maxShowFiles = 5
+
+// runShow writes the full breakdown of a single driver: roles it uses in the
+// annotations and fixtures, unmapped native types, DSL operations, the SDK
+// version and the fixtures statistics. Analyses that fail are reported in
+// place, since the driver is likely being worked on.
+func runShow(w io.Writer, lang string) error {
+	pkg, ok := OfficialDriver[lang]
+	if !ok {
+		return fmt.Errorf("unknown driver: %q (known: %s)", lang, strings.Join(languages(), ", "))
+	}
+	roles, err := findRoles()
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "# %s driver\n\n", strings.Title(lang))
+
+	dir, err := driverDir(pkg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "SDK version: %s\n", driverSDKVersion(dir))
+
+	buf.WriteString("\n## Fixtures\n\n")
+	if st, err := findCorpusStats(lang, pkg); err != nil {
+		fmt.Fprintf(buf, "Error: %v\n", err)
+	} else {
+		fmt.Fprintf(buf, "Files|Nodes|Annotated|Avg depth|Max depth|Max fan-out|Source bytes\n-|-|-|-|-|-|-\n%d|%d|%s|%.1f|%d|%d|%d\n",
+			st.Files, st.Nodes, st.AnnotatedRatio(), st.AvgDepth(), st.MaxDepth, st.MaxFanOut, st.SourceBytes)
+	}
+
+	buf.WriteString("\n## Roles\n\n")
+	if err := findUsage(lang, pkg, roles); err != nil {
+		fmt.Fprintf(buf, "Annotations error: %v\n\n", err)
+	}
+	unknown := make(UnknownRoles)
+	if err := findFixtureUsage(lang, pkg, roles, unknown); err != nil {
+		fmt.Fprintf(buf, "Fixtures error: %v\n\n", err)
+	}
+	writeShowRoles(buf, roles, lang)
+	var names []string
+	for name := range unknown[lang] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pos := unknown[lang][name]
+		fmt.Fprintf(buf, "\nUnknown role `%s`: %d times, first in `%s:%d`\n",
+			name, len(pos), filepath.Base(pos[0].Filename), pos[0].Line)
+	}
+
+	buf.WriteString("\n## Unmapped native types\n\n")
+	if m, err := findUnmapped(pkg); err != nil {
+		fmt.Fprintf(buf, "Error: %v\n", err)
+	} else if len(m) == 0 {
+		buf.WriteString("All native types are mapped.\n")
+	} else {
+		writeTypeCounts(buf, m)
+	}
+
+	buf.WriteString("\n## DSL operations\n\n")
+	if m, err := findDSLUsage(pkg); err != nil {
+		fmt.Fprintf(buf, "Error: %v\n", err)
+	} else {
+		ops := make([]string, 0, len(m))
+		for op := range m {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		buf.WriteString("Operation|Uses\n-|-\n")
+		for _, op := range ops {
+			fmt.Fprintf(buf, "`%s`|%d\n", op, m[op])
+		}
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// writeShowRoles writes the roles used by the driver along with the fixture
+// files they are used in.
+func writeShowRoles(buf *bytes.Buffer, roles Roles, lang string) {
+	var ann, fix int
+	for _, role := range roles {
+		if role.IsUsedBy(lang) {
+			ann++
+		}
+		if role.IsUsedInFixtures(lang) {
+			fix++
+		}
+	}
+	fmt.Fprintf(buf, "Used in annotations: %d/%d, in fixtures: %d/%d, score: %.1f%%\n\n",
+		ann, len(roles), fix, len(roles), roles.Score(lang))
+
+	buf.WriteString("Role|Annotations|Fixture nodes|Fixture files\n-|-|-|-\n")
+	for _, role := range roles {
+		if !role.IsUsedBy(lang) && !role.IsUsedInFixtures(lang) {
+			continue
+		}
+		seen := make(map[string]bool)
+		var files []string
+		for _, pos := range role.Fixtures[lang] {
+			name := filepath.Base(pos.Filename)
+			if !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+		sort.Strings(files)
+		if len(files) > maxShowFiles {
+			files = append(files[:maxShowFiles], fmt.Sprintf("and %d more", len(files)-maxShowFiles))
+		}
+		fmt.Fprintf(buf, "%s|%d|%d|%s\n", role.Name,
+			len(role.Languages[lang]), len(role.Fixtures[lang]), strings.Join(files, ", "))
+	}
+}
+
+// driverSDKVersion returns the version of the SDK required by the go.mod file
+// of the driver, or locked by its Gopkg.lock file for drivers using dep, or
+// "unknown" if it cannot be found.
+func driverSDKVersion(dir string) string {
+	if vers := goModSDKVersion(filepath.Join(dir, "go.mod")); vers != "" {
+		return vers
+	}
+	if vers := gopkgLockSDKVersion(filepath.Join(dir, "Gopkg.lock")); vers != "" {
+		return vers
+	}
+	return "unknown"
+}
+
+// goModSDKVersion returns the version of the SDK required by the go.mod file,
+// or an empty string if the file or the requirement doesn't exist.
+func goModSDKVersion(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(sc.Text()), "require "))
+		if len(fields) >= 2 && strings.Contains(fields[0], "bblfsh/sdk") {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// gopkgLockSDKVersion returns the version of the SDK locked by the Gopkg.lock
+// file, or its revision if the version is not tagged. It returns an empty
+// string if the file or the project doesn't exist.
+func gopkgLockSDKVersion(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var (
+		sdk               bool
+		version, revision string
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			if sdk {
+				break
+			}
+			version, revision = "", ""
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			continue
+		}
+		val := strings.Trim(strings.TrimSpace(line[i+1:]), `"`)
+		switch strings.TrimSpace(line[:i]) {
+		case "name":
+			sdk = strings.Contains(val, "bblfsh/sdk")
+		case "version":
+			version = val
+		case "revision":
+			revision = val
+		}
+	}
+	if !sdk {
+		return ""
+	} else if version != "" {
+		return version
+	}
+	return revision
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("stats", findCorpusStats, StatsReport))
+}
+
+// FanOutBuckets are the upper bounds of the number of children for the
+// fan-out distribution. The last bucket contains all larger nodes.
+var FanOutBuckets = []int{0, 1, 3, 7, 15}
+
+// CorpusStats contains statistics of the fixtures of a driver.
+type CorpusStats struct {
+	Files       int
+	Nodes       int
+	MaxDepth    int
+	TotalDepth  int
+	SourceBytes int64
+	// Annotated is the number of nodes with at least one role.
+	Annotated int
+	// MaxFanOut is the largest number of children of a node.
+	MaxFanOut int
+	// FanOut is the number of nodes in each of FanOutBuckets, and
+	// the number of larger nodes.
+	FanOut []int
+}
+
+// AnnotatedRatio returns the share of nodes with roles, in percent.
+func (st *CorpusStats) AnnotatedRatio() string {
+	return percent(st.Annotated, st.Nodes)
+}
+
+func (st *CorpusStats) addFanOut(n int) {
+	if st.FanOut == nil {
+		st.FanOut = make([]int, len(FanOutBuckets)+1)
+	}
+	if n > st.MaxFanOut {
+		st.MaxFanOut = n
+	}
+	i := sort.SearchInts(FanOutBuckets, n)
+	st.FanOut[i]++
+}
+
+// fanOutLabels returns the names of the fan-out buckets.
+func fanOutLabels() []string {
+	var (
+		labels []string
+		from   int
+	)
+	for _, to := range FanOutBuckets {
+		if from == to {
+			labels = append(labels, fmt.Sprint(to))
+		} else {
+			labels = append(labels, fmt.Sprintf("%d-%d", from, to))
+		}
+		from = to + 1
+	}
+	return append(labels, fmt.Sprintf("%d+", from))
+}
+
+// AvgDepth returns an average depth of the fixture trees.
+func (st *CorpusStats) AvgDepth() float64 {
+	if st.Files == 0 {
+		return 0
+	}
+	return float64(st.TotalDepth) / float64(st.Files)
+}
+
+// findCorpusStats collects statistics of the fixtures of a driver.
+func findCorpusStats(language, pkg string) (*CorpusStats, error) {
+	st := &CorpusStats{}
+	err := walkFixtureTrees(language, pkg, func(path string, root *Node) {
+		st.Files++
+		root.Walk(func(n *Node) {
+			st.Nodes++
+			if len(distinctRoles(n.Roles)) != 0 {
+				st.Annotated++
+			}
+			st.addFanOut(len(n.Children))
+		})
+		d := root.Depth()
+		st.TotalDepth += d
+		if d > st.MaxDepth {
+			st.MaxDepth = d
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := findFixtures(pkg, ".source")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range sources {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		st.SourceBytes += fi.Size()
+	}
+	return st, nil
+}
+
+const statsHeader = "" +
+	"# Fixtures statistics\n\n" +
+	"The table shows the size of the fixtures corpus of each driver, and the " +
+	"share of nodes with at least one role. Unlike the roles presence, the " +
+	"share shows how much of the native AST is actually mapped.\n\n"
+
+// StatsReport renders the fixtures statistics for each language.
+func StatsReport(stats map[string]*CorpusStats) string {
+	buf := bytes.NewBuffer([]byte(statsHeader))
+	buf.WriteString("Language|Files|Nodes|Annotated|Avg depth|Max depth|Max fan-out|Source bytes\n")
+	buf.WriteString("-|-|-|-|-|-|-|-\n")
+	for _, lang := range languages() {
+		st, ok := stats[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "%s|%d|%d|%s|%.1f|%d|%d|%d\n",
+			strings.Title(lang), st.Files, st.Nodes, st.AnnotatedRatio(),
+			st.AvgDepth(), st.MaxDepth, st.MaxFanOut, st.SourceBytes,
+		)
+	}
+
+	buf.WriteString("\n## Fan-out\n\n" +
+		"The share of nodes by the number of their children.\n\n")
+	labels := fanOutLabels()
+	buf.WriteString("Language|" + strings.Join(labels, "|") + "\n")
+	buf.WriteString("-" + strings.Repeat("|-", len(labels)) + "\n")
+	for _, lang := range languages() {
+		st, ok := stats[lang]
+		if !ok || st.FanOut == nil {
+			continue
+		}
+		buf.WriteString(strings.Title(lang))
+		for _, n := range st.FanOut {
+			buf.WriteString("|" + percent(n, st.Nodes))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("gh-summary", fixtureUsage, func(w io.Writer, roles Roles, _ map[string]map[string][]token.Position) error {
+		var regressions []Regression
+		if *previous != "" {
+			prev, err := loadPreviousFile(*previous)
+			if err != nil {
+				return err
+			}
+			regressions = findRegressions(roles, prev)
+		}
+		// the runner reads workflow commands from both outputs, while
+		// the standard output is usually appended to $GITHUB_STEP_SUMMARY
+		return printReport(w, func() string { return roles.GitHubSummary(regressions, os.Stderr) })
+	}))
+}
+
+var (
+	previous = flag.String("previous", "", "previously generated roles report to detect coverage regressions against")
+)
+
+// loadPreviousUsage reads the roles table of a previously generated roles
+// report and returns the set of languages using each role. Only the default
+// table layout is supported, i.e. roles as rows and languages as columns.
+func loadPreviousUsage(r io.Reader) (map[string]map[string]bool, error) {
+	var (
+		langs []string
+		out   = make(map[string]map[string]bool)
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		cells := strings.Split(sc.Text(), "|")
+		if langs == nil {
+			if len(cells) > 1 && cells[0] == "Role" {
+				for _, c := range cells[1:] {
+					langs = append(langs, strings.ToLower(strings.TrimSpace(c)))
+				}
+			}
+			continue
+		}
+		if len(cells) != len(langs)+1 {
+			if len(out) != 0 {
+				// end of the table
+				break
+			}
+			continue
+		}
+		name := cells[0]
+		end := strings.Index(name, "]")
+		if !strings.HasPrefix(name, "[") || end < 0 {
+			// delimiter row or subtotals of the grouped table
+			continue
+		}
+		name = name[1:end]
+		used := make(map[string]bool)
+		for i, c := range cells[1:] {
+			c = strings.TrimSpace(c)
+			if c != "" && c != "✗" && c != "❌" {
+				used[langs[i]] = true
+			}
+		}
+		out[name] = used
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if langs == nil {
+		return nil, fmt.Errorf("roles table not found")
+	}
+	return out, nil
+}
+
+// Regression is a role that was used by a driver in the previous report,
+// but is not used anymore.
+type Regression struct {
+	Language string
+	Role     string
+}
+
+func findRegressions(roles Roles, prev map[string]map[string]bool) []Regression {
+	var out []Regression
+	for _, role := range roles {
+		for lang, used := range prev[role.Name] {
+			if _, ok := OfficialDriver[lang]; ok && used && !role.IsUsedBy(lang) {
+				out = append(out, Regression{Language: lang, Role: role.Name})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Language != out[j].Language {
+			return out[i].Language < out[j].Language
+		}
+		return out[i].Role < out[j].Role
+	})
+	return out
+}
+
+// GitHubSummary renders a short coverage summary for the GitHub Actions job
+// summary. Regressions are also written to annotations as workflow commands,
+// thus they are shown in the Actions UI.
+func (r Roles) GitHubSummary(regressions []Regression, annotations io.Writer) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("## Roles coverage\n\n")
+	buf.WriteString("Language|Annotations|Fixtures\n-|-|-\n")
+	for _, lang := range languages() {
+		var ann, fix int
+		for _, role := range r {
+			if role.IsUsedBy(lang) {
+				ann++
+			}
+			if role.IsUsedInFixtures(lang) {
+				fix++
+			}
+		}
+		fmt.Fprintf(buf, "%s|%d/%d (%s)|%d/%d (%s)\n", strings.Title(lang),
+			ann, len(r), percent(ann, len(r)),
+			fix, len(r), percent(fix, len(r)),
+		)
+	}
+	if len(regressions) == 0 {
+		return buf.String()
+	}
+	buf.WriteString("\n### Regressions\n\n")
+	for _, reg := range regressions {
+		fmt.Fprintf(buf, "- %s no longer uses the `%s` role\n", strings.Title(reg.Language), reg.Role)
+		fmt.Fprintf(annotations, "::warning title=Roles coverage regression::%s driver no longer uses the %s role\n",
+			strings.Title(reg.Language), reg.Role)
+	}
+	return buf.String()
+}
+
+func loadPreviousFile(path string) (map[string]map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	prev, err := loadPreviousUsage(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return prev, nil
+}
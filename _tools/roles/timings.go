@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phases of the run, in the order of execution.
+const (
+	PhaseFetch    = "fetch"
+	PhaseCode     = "code"
+	PhaseFixtures = "fixtures"
+	PhaseRender   = "render"
+)
+
+var phases = []string{PhaseFetch, PhaseCode, PhaseFixtures, PhaseRender}
+
+// phaseTimings is the wall-clock time spent on each phase of the run, per
+// driver. Phases that are not specific to a driver are recorded with an empty
+// language.
+type phaseTimings struct {
+	mu sync.Mutex
+	d  map[string]map[string]time.Duration
+}
+
+var timings = &phaseTimings{d: make(map[string]map[string]time.Duration)}
+
+// track starts measuring the phase for a language. The returned function
+// stops it. Fixtures of a driver are decoded in parallel, thus the phase
+// may take less time than the sum of its parts.
+func (t *phaseTimings) track(phase, lang string) func() {
+	start := time.Now()
+	return func() {
+		d := time.Since(start)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		m := t.d[phase]
+		if m == nil {
+			m = make(map[string]time.Duration)
+			t.d[phase] = m
+		}
+		m[lang] += d
+	}
+}
+
+// seconds returns the time spent on each phase per language, in seconds.
+func (t *phaseTimings) seconds() map[string]map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]map[string]float64, len(t.d))
+	for phase, m := range t.d {
+		out[phase] = make(map[string]float64, len(m))
+		for lang, d := range m {
+			out[phase][lang] = d.Seconds()
+		}
+	}
+	return out
+}
+
+// write prints the time spent on each phase, followed by the breakdown per
+// language.
+func (t *phaseTimings) write(w io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.d) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "timings:")
+	for _, phase := range phases {
+		m := t.d[phase]
+		if len(m) == 0 {
+			continue
+		}
+		var (
+			total time.Duration
+			langs []string
+		)
+		for lang, d := range m {
+			total += d
+			if lang != "" {
+				langs = append(langs, lang)
+			}
+		}
+		sort.Strings(langs)
+		var parts []string
+		for _, lang := range langs {
+			parts = append(parts, fmt.Sprintf("%s %v", lang, m[lang].Round(time.Millisecond)))
+		}
+		fmt.Fprintf(w, "  %-9s %v", phase, total.Round(time.Millisecond))
+		if len(parts) != 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(parts, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// render measures the rendering of the report.
+func render(fn func() string) string {
+	defer timings.track(PhaseRender, "")()
+	return fn()
+}
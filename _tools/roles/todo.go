@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerRoles(newRolesAnalyzer("todo", withoutRoles(findUnannotated), func(w io.Writer, roles Roles, unannotated map[string]map[string]int) error {
+		return printReport(w, func() string { return roles.TODOReport(unannotated) })
+	}))
+}
+
+// SDKDocPattern is a link to the documentation of an identifier of the SDK
+// uast package.
+const SDKDocPattern = "https://godoc.org/" + UASTPackage + "#%s"
+
+const todoHeader = "" +
+	"# Contribution TODO lists\n\n" +
+	"The checklists of the work left in each driver. Every item is small " +
+	"enough to be a good first issue: pick one and [join the community](../community.md)!\n"
+
+// TODOReport renders a checklist for each language with the roles the
+// driver does not assign yet and the native types left unannotated in its
+// fixtures.
+func (r Roles) TODOReport(unannotated map[string]map[string]int) string {
+	buf := bytes.NewBuffer([]byte(todoHeader))
+	langs := languages()
+	for _, lang := range langs {
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		fmt.Fprintf(buf, "Annotation rules are defined in [annotation.go](%s).\n",
+			fmt.Sprintf(GitHubFilePattern, lang))
+
+		if missing := r.missingRoles(lang, langs); len(missing) != 0 {
+			buf.WriteString("\n### Roles\n\n")
+			for _, role := range missing {
+				fmt.Fprintf(buf, "- [ ] Annotate nodes with the [%s](roles.md#%s) role ([SDK](%s))\n",
+					role.Name, strings.ToLower(role.Name), fmt.Sprintf(SDKDocPattern, role.Name))
+			}
+		}
+
+		types := unannotated[lang]
+		if len(types) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(types))
+		for name := range types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		buf.WriteString("\n### Unannotated nodes\n\n")
+		for _, name := range names {
+			fmt.Fprintf(buf, "- [ ] Annotate `%s` nodes (%d in fixtures)\n", name, types[name])
+		}
+	}
+	return buf.String()
+}
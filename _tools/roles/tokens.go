@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("tokens", findTokens, TokensReport))
+}
+
+// TokenRoles are the roles of the nodes that are expected to keep the token
+// of the source code, i.e. names and values.
+var TokenRoles = []string{"Identifier", "String", "Number", "Boolean", "Comment"}
+
+// TokenCounts is the number of nodes with the role and the number of those
+// that have a non-empty token.
+type TokenCounts struct {
+	Nodes     int
+	WithToken int
+}
+
+// findTokens counts the nodes with roles that are expected to keep the token,
+// and the nodes that actually have one, in the fixtures of a driver.
+func findTokens(language, pkg string) (map[string]*TokenCounts, error) {
+	out := make(map[string]*TokenCounts, len(TokenRoles))
+	for _, role := range TokenRoles {
+		out[role] = &TokenCounts{}
+	}
+	err := walkFixtures(language, pkg, func(path string, n *Node) {
+		for _, r := range distinctRoles(n.Roles) {
+			c, ok := out[r]
+			if !ok {
+				continue
+			}
+			c.Nodes++
+			if strings.TrimSpace(n.Token) != "" {
+				c.WithToken++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+const tokensHeader = "" +
+	"# Tokens preservation\n\n" +
+	"The table shows the share of nodes in the driver fixtures (`*.uast` files) " +
+	"that keep the token of the source code, such as the name of the identifier " +
+	"or the text of the comment. Values marked with ⚠ mean that the driver drops " +
+	"some of the tokens during the normalization. Empty string literals have " +
+	"no token as well, thus a value slightly below 100% is expected for `String`.\n\n"
+
+// TokensReport renders the tokens preservation for each language.
+func TokensReport(tokens map[string]map[string]*TokenCounts) string {
+	buf := bytes.NewBuffer([]byte(tokensHeader))
+	buf.WriteString("Language|" + strings.Join(TokenRoles, "|") + "\n")
+	buf.WriteString("-" + strings.Repeat("|-", len(TokenRoles)) + "\n")
+	for _, lang := range languages() {
+		m, ok := tokens[lang]
+		if !ok {
+			continue
+		}
+		buf.WriteString(strings.Title(lang))
+		for _, role := range TokenRoles {
+			c := m[role]
+			cell := "-"
+			if c.Nodes != 0 {
+				cell = percent(c.WithToken, c.Nodes)
+				if c.WithToken < c.Nodes {
+					cell += " ⚠"
+				}
+			}
+			fmt.Fprintf(buf, "|%s", cell)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Node is a UAST node decoded from the text representation used by the
+// driver fixtures (*.uast files).
+type Node struct {
+	InternalType  string
+	Roles         []string
+	Token         string
+	Properties    map[string]string
+	StartPosition *Position
+	EndPosition   *Position
+	Children      []*Node
+	// Line is the line of the fixture file where the node starts.
+	Line int
+}
+
+// Position is a position of a node in the source file.
+type Position struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order.
+func (n *Node) Walk(fn func(n *Node)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Depth returns the depth of the tree rooted at the node.
+func (n *Node) Depth() int {
+	max := 0
+	for _, c := range n.Children {
+		if d := c.Depth(); d > max {
+			max = d
+		}
+	}
+	return max + 1
+}
+
+type frameKind int
+
+const (
+	frameNode frameKind = iota
+	frameChildren
+	framePosition
+	frameProperties
+	// frameUnknown is a block of an unknown field; its content is skipped
+	frameUnknown
+)
+
+type frame struct {
+	kind frameKind
+	node *Node
+	pos  *Position
+}
+
+// ParseFixture decodes a UAST in the text format produced by the SDK:
+//
+//	Module {
+//	.  Roles: File
+//	.  Children: {
+//	.  .  0: Name {
+//	.  .  .  Roles: Identifier,Expression
+//	.  .  .  TOKEN "a"
+//	...
+func ParseFixture(r io.Reader) (*Node, error) {
+	return parseFixture(r, true, nil)
+}
+
+// ScanFixture decodes a UAST in the same format as ParseFixture, but instead
+// of building the tree it calls fn for each node as soon as the node is
+// decoded, i.e. children are visited before their parents. Children of the
+// nodes are not kept, thus the memory used does not depend on the file size.
+func ScanFixture(r io.Reader, fn func(n *Node)) error {
+	_, err := parseFixture(r, false, fn)
+	return err
+}
+
+// parseFixture decodes a UAST fixture. Child nodes are attached to their
+// parents only if keep is set. If fn is set, it's called for each node once
+// the node is fully decoded.
+func parseFixture(r io.Reader, keep bool, fn func(n *Node)) (*Node, error) {
+	var (
+		root  *Node
+		stack []frame
+		line  int
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		line++
+		s := strings.TrimLeft(sc.Text(), ". \t")
+		if s == "" {
+			continue
+		}
+		if s == "}" {
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: unexpected '}'", line)
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.kind == frameNode && fn != nil {
+				fn(top.node)
+			}
+			continue
+		}
+		if len(stack) == 0 {
+			if root != nil {
+				return nil, fmt.Errorf("line %d: unexpected data after the root node", line)
+			}
+			typ, ok := parseNodeHeader(s)
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a node, got %q", line, s)
+			}
+			root = &Node{InternalType: typ, Line: line}
+			stack = append(stack, frame{kind: frameNode, node: root})
+			continue
+		}
+		top := stack[len(stack)-1]
+		switch top.kind {
+		case frameChildren:
+			i := strings.Index(s, ": ")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: expected a child node, got %q", line, s)
+			}
+			typ, ok := parseNodeHeader(s[i+2:])
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a child node, got %q", line, s)
+			}
+			n := &Node{InternalType: typ, Line: line}
+			if keep {
+				top.node.Children = append(top.node.Children, n)
+			}
+			stack = append(stack, frame{kind: frameNode, node: n})
+		case framePosition:
+			k, v := splitField(s)
+			iv, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			switch k {
+			case "Offset":
+				top.pos.Offset = iv
+			case "Line":
+				top.pos.Line = iv
+			case "Col":
+				top.pos.Col = iv
+			}
+		case frameProperties:
+			k, v := splitField(s)
+			if top.node.Properties == nil {
+				top.node.Properties = make(map[string]string)
+			}
+			top.node.Properties[k] = v
+		case frameUnknown:
+			if strings.HasSuffix(s, "{") {
+				stack = append(stack, frame{kind: frameUnknown})
+			}
+		case frameNode:
+			n := top.node
+			switch {
+			case strings.HasPrefix(s, "TOKEN "):
+				// the SDK writes the token verbatim in quotes, thus it may
+				// span several lines and contain quotes or braces
+				tok := strings.TrimPrefix(s, "TOKEN ")
+				if strings.HasPrefix(tok, `"`) {
+					for len(tok) < 2 || !strings.HasSuffix(tok, `"`) {
+						if !sc.Scan() {
+							return nil, fmt.Errorf("line %d: unterminated token", line)
+						}
+						line++
+						tok += "\n" + sc.Text()
+					}
+					tok = tok[1 : len(tok)-1]
+				}
+				n.Token = tok
+			case s == "Children: {":
+				stack = append(stack, frame{kind: frameChildren, node: n})
+			case s == "Properties: {":
+				stack = append(stack, frame{kind: frameProperties, node: n})
+			case s == "StartPosition: {":
+				n.StartPosition = &Position{}
+				stack = append(stack, frame{kind: framePosition, pos: n.StartPosition})
+			case s == "EndPosition: {":
+				n.EndPosition = &Position{}
+				stack = append(stack, frame{kind: framePosition, pos: n.EndPosition})
+			case strings.HasSuffix(s, "{"):
+				stack = append(stack, frame{kind: frameUnknown})
+			default:
+				k, v := splitField(s)
+				if k == "Roles" {
+					for _, r := range strings.Split(v, ",") {
+						if r = strings.TrimSpace(r); r != "" {
+							n.Roles = append(n.Roles, r)
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no nodes found")
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	return root, nil
+}
+
+// parseNodeHeader parses a node header in form of "Type {".
+func parseNodeHeader(s string) (string, bool) {
+	if !strings.HasSuffix(s, "{") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimSuffix(s, "{")), true
+}
+
+func splitField(s string) (key, val string) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i+1:])
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixture joins the lines of a fixture in the text format of the SDK.
+func fixture(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestParseFixture(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		exp  *Node
+	}{
+		{
+			name: "nested",
+			in: fixture(
+				"Module {",
+				".  Roles: File",
+				".  Children: {",
+				".  .  0: Name {",
+				".  .  .  Roles: Identifier,Expression",
+				`.  .  .  TOKEN "a"`,
+				".  .  }",
+				".  .  1: Call {",
+				".  .  .  Children: {",
+				".  .  .  .  0: Name {",
+				`.  .  .  .  .  TOKEN "f"`,
+				".  .  .  .  }",
+				".  .  .  }",
+				".  .  }",
+				".  }",
+				"}",
+			),
+			exp: &Node{
+				InternalType: "Module", Roles: []string{"File"}, Line: 1,
+				Children: []*Node{
+					{InternalType: "Name", Roles: []string{"Identifier", "Expression"}, Token: "a", Line: 4},
+					{InternalType: "Call", Line: 8, Children: []*Node{
+						{InternalType: "Name", Token: "f", Line: 10},
+					}},
+				},
+			},
+		},
+		{
+			name: "properties and positions",
+			in: fixture(
+				"Str {",
+				".  StartPosition: {",
+				".  .  Offset: 4",
+				".  .  Line: 1",
+				".  .  Col: 5",
+				".  }",
+				".  EndPosition: {",
+				".  .  Offset: 7",
+				".  .  Line: 2",
+				".  .  Col: 1",
+				".  }",
+				".  Properties: {",
+				".  .  internalRole: value",
+				".  .  kind: a: b",
+				".  }",
+				"}",
+			),
+			exp: &Node{
+				InternalType:  "Str",
+				StartPosition: &Position{Offset: 4, Line: 1, Col: 5},
+				EndPosition:   &Position{Offset: 7, Line: 2, Col: 1},
+				Properties:    map[string]string{"internalRole": "value", "kind": "a: b"},
+				Line:          1,
+			},
+		},
+		{
+			name: "verbatim tokens",
+			in: fixture(
+				"Block {",
+				".  Children: {",
+				".  .  0: Str {",
+				`.  .  .  TOKEN "}"`,
+				".  .  }",
+				".  .  1: Str {",
+				`.  .  .  TOKEN "say "hi"\n"`,
+				".  .  }",
+				".  .  2: Comment {",
+				`.  .  .  TOKEN "/* a`,
+				"}",
+				` */"`,
+				".  .  }",
+				".  }",
+				"}",
+			),
+			exp: &Node{
+				InternalType: "Block", Line: 1,
+				Children: []*Node{
+					{InternalType: "Str", Token: "}", Line: 3},
+					{InternalType: "Str", Token: `say "hi"\n`, Line: 6},
+					{InternalType: "Comment", Token: "/* a\n}\n */", Line: 9},
+				},
+			},
+		},
+		{
+			name: "unknown blocks",
+			in: fixture(
+				"Module {",
+				".  Extra: {",
+				".  .  Nested: {",
+				".  .  .  Key: value",
+				".  .  }",
+				".  }",
+				".  Children: {",
+				".  .  0: Name {",
+				".  .  }",
+				".  }",
+				"}",
+			),
+			exp: &Node{
+				InternalType: "Module", Line: 1,
+				Children: []*Node{{InternalType: "Name", Line: 8}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n, err := ParseFixture(strings.NewReader(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(n, c.exp) {
+				t.Errorf("unexpected tree:\n%s", dumpNode(n, ""))
+			}
+		})
+	}
+}
+
+func TestParseFixtureErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{name: "empty", in: "", err: "no nodes found"},
+		{name: "unbalanced close", in: fixture("}"), err: "line 1: unexpected '}'"},
+		{name: "unclosed", in: fixture("Module {", ".  Children: {"), err: "unexpected end of file"},
+		{name: "trailing data", in: fixture("Module {", "}", "Module {", "}"), err: "line 3: unexpected data after the root node"},
+		{name: "not a node", in: fixture("Roles: File"), err: `line 1: expected a node, got "Roles: File"`},
+		{name: "bad child", in: fixture("Module {", ".  Children: {", ".  .  Name", ".  }", "}"), err: `line 3: expected a child node, got "Name"`},
+		{name: "bad position", in: fixture("Module {", ".  StartPosition: {", ".  .  Line: x", ".  }", "}"), err: "line 3: "},
+		{name: "unterminated token", in: fixture("Module {", `.  TOKEN "a`, "}"), err: "line 3: unterminated token"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseFixture(strings.NewReader(c.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.HasPrefix(err.Error(), c.err) {
+				t.Errorf("expected %q, got %q", c.err, err)
+			}
+			if serr := ScanFixture(strings.NewReader(c.in), func(*Node) {}); serr == nil || serr.Error() != err.Error() {
+				t.Errorf("ScanFixture returned a different error: %v", serr)
+			}
+		})
+	}
+}
+
+func TestScanFixture(t *testing.T) {
+	in := fixture(
+		"Module {",
+		".  Children: {",
+		".  .  0: Name {",
+		`.  .  .  TOKEN "}"`,
+		".  .  }",
+		".  .  1: Call {",
+		".  .  .  Children: {",
+		".  .  .  .  0: Name {",
+		".  .  .  .  }",
+		".  .  .  }",
+		".  .  }",
+		".  }",
+		"}",
+	)
+	root, err := ParseFixture(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed []string
+	root.Walk(func(n *Node) {
+		parsed = append(parsed, n.InternalType)
+	})
+	var scanned []string
+	err = ScanFixture(strings.NewReader(in), func(n *Node) {
+		if len(n.Children) != 0 {
+			t.Errorf("%s: children are kept", n.InternalType)
+		}
+		scanned = append(scanned, n.InternalType)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(scanned) {
+		t.Fatalf("parsed %d nodes, scanned %d", len(parsed), len(scanned))
+	}
+	// children are visited before their parents
+	if exp := []string{"Name", "Name", "Call", "Module"}; !reflect.DeepEqual(scanned, exp) {
+		t.Errorf("expected %v, got %v", exp, scanned)
+	}
+}
+
+// dumpNode prints the tree for the test failures.
+func dumpNode(n *Node, indent string) string {
+	s := indent + n.InternalType + " " + strings.Join(n.Roles, ",") + " " + n.Token + "\n"
+	for _, c := range n.Children {
+		s += dumpNode(c, indent+"  ")
+	}
+	return s
+}
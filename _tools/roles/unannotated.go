@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("unannotated", findUnannotated, UnannotatedReport))
+}
+
+const unannotatedHeader = "" +
+	"# Unannotated node types\n\n" +
+	"The list of native node types that are still left unannotated in the " +
+	"driver fixtures, i.e. no annotation rule assigns any roles to them. " +
+	"Those are the constructs that still need annotation rules, thus it's " +
+	"a good place to start if you want to [help us](../community.md).\n"
+
+// UnannotatedReport renders the number of unannotated nodes of each native
+// type for each language.
+func UnannotatedReport(types map[string]map[string]int) string {
+	buf := bytes.NewBuffer([]byte(unannotatedHeader))
+	for _, lang := range languages() {
+		m, ok := types[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		if len(m) == 0 {
+			buf.WriteString("All node types are annotated.\n")
+			continue
+		}
+		writeTypeCounts(buf, m)
+	}
+	return buf.String()
+}
+
+// writeTypeCounts writes a table with the number of nodes of each type.
+// The most frequent types are listed first.
+func writeTypeCounts(buf *bytes.Buffer, m map[string]int) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if m[a] != m[b] {
+			return m[a] > m[b]
+		}
+		return a < b
+	})
+	buf.WriteString("Type|Nodes\n-|-\n")
+	for _, name := range names {
+		fmt.Fprintf(buf, "`%s`|%d\n", name, m[name])
+	}
+}
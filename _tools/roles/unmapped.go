@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("unmapped", byPackage(findUnmapped), UnmappedReport))
+}
+
+// InternalTypeKey is the field of the node converter in the normalizer
+// package that names the native AST field with the node type.
+const InternalTypeKey = "InternalTypeKey"
+
+// findUnmapped returns the number of nodes of each native type found in the
+// native fixtures of a driver that are never mentioned in the normalizer
+// package, thus cannot be matched by any of the annotation rules.
+func findUnmapped(pkg string) (map[string]int, error) {
+	key, mapped, err := findMappedTypes(pkg)
+	if err != nil {
+		return nil, err
+	}
+	files, err := findFixtures(pkg, ".native")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int)
+	for _, path := range files {
+		counts, err := countNativeTypes(path, key)
+		if err != nil {
+			return nil, err
+		}
+		for typ, n := range counts {
+			if !mapped[typ] {
+				out[typ] += n
+			}
+		}
+	}
+	return out, nil
+}
+
+// findMappedTypes returns the native field that contains the node type and
+// all the strings used in the normalizer package, either as literals or as
+// constants. Annotation rules refer to native types this way.
+func findMappedTypes(pkg string) (string, map[string]bool, error) {
+	prog, result, err := loadDriver(pkg)
+	if err != nil {
+		return "", nil, err
+	}
+	var (
+		key    string
+		mapped = make(map[string]bool)
+	)
+	for _, obj := range result.Uses {
+		if c, ok := obj.(*types.Const); ok && c.Val().Kind() == constant.String {
+			mapped[constant.StringVal(c.Val())] = true
+		}
+	}
+	for _, f := range driverPackage(prog, pkg).Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BasicLit:
+				if n.Kind == token.STRING {
+					if s, err := strconv.Unquote(n.Value); err == nil {
+						mapped[s] = true
+					}
+				}
+			case *ast.KeyValueExpr:
+				id, ok := n.Key.(*ast.Ident)
+				if !ok || id.Name != InternalTypeKey {
+					break
+				}
+				if v, ok := result.Types[n.Value]; ok && v.Value != nil && v.Value.Kind() == constant.String {
+					key = constant.StringVal(v.Value)
+				} else if lit, ok := n.Value.(*ast.BasicLit); ok {
+					key, _ = strconv.Unquote(lit.Value)
+				}
+			}
+			return true
+		})
+	}
+	if key == "" {
+		return "", nil, fmt.Errorf("%s: cannot find %s of the node converter", pkg, InternalTypeKey)
+	}
+	return key, mapped, nil
+}
+
+// countNativeTypes counts the nodes of each type in a native fixture.
+func countNativeTypes(path, key string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var v interface{}
+	if err := json.NewDecoder(f).Decode(&v); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	counts := make(map[string]int)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if typ, ok := v[key].(string); ok {
+				counts[typ]++
+			}
+			for _, c := range v {
+				walk(c)
+			}
+		case []interface{}:
+			for _, c := range v {
+				walk(c)
+			}
+		}
+	}
+	walk(v)
+	return counts, nil
+}
+
+const unmappedHeader = "" +
+	"# Unmapped native node types\n\n" +
+	"The list of native node types found in the driver fixtures (`*.native` " +
+	"files) that are not referenced by the driver normalizer, thus no " +
+	"annotation rule can match them. The most frequent types are listed " +
+	"first, which makes the list a prioritized TODO for the driver authors.\n"
+
+// UnmappedReport renders the number of nodes of each unmapped native type
+// for each language.
+func UnmappedReport(types map[string]map[string]int) string {
+	buf := bytes.NewBuffer([]byte(unmappedHeader))
+	for _, lang := range languages() {
+		m, ok := types[lang]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", strings.Title(lang))
+		if len(m) == 0 {
+			buf.WriteString("All native node types are mapped.\n")
+			continue
+		}
+		writeTypeCounts(buf, m)
+	}
+	return buf.String()
+}
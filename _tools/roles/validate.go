@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// validateFixtures decodes all native, annotated and semantic fixtures of
+// a driver and returns the list of errors for files that cannot be decoded.
+func validateFixtures(pkg string) ([]error, error) {
+	var errs []error
+	for _, ext := range []string{".native", ".uast", ".sem.uast"} {
+		files, err := findFixtures(pkg, ext)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			if err := validateFixture(path, ext); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", path, err))
+			}
+		}
+	}
+	return errs, nil
+}
+
+func validateFixture(path, ext string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch ext {
+	case ".uast":
+		// the tree is not needed, thus it's not kept in memory
+		return ScanFixture(f, func(n *Node) {})
+	case ".sem.uast":
+		if *stream {
+			if err := scanSemantic(f); err != errNotFlow {
+				return err
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		return validateSemantic(f)
+	}
+
+	// native fixtures are JSON documents
+	dec := json.NewDecoder(f)
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if err := dec.Decode(&v); err != io.EOF {
+		return fmt.Errorf("unexpected data after the native AST")
+	}
+	return nil
+}
+
+// validateSemantic decodes a semantic UAST fixture, that is a YAML document
+// with the root node of the tree.
+func validateSemantic(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if _, ok := root["@type"]; !ok {
+		return fmt.Errorf("the root node has no @type")
+	}
+	return nil
+}
+
+// runValidate validates fixtures of all drivers and prints decoding errors.
+// It returns false if any fixture fails to decode, and an error if the
+// fixtures cannot be listed.
+func runValidate() (bool, error) {
+	ok := true
+	for _, lang := range languages() {
+		errs, err := validateFixtures(OfficialDriver[lang])
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", lang, err)
+		}
+		for _, err := range errs {
+			fmt.Printf("%s: %v\n", lang, err)
+		}
+		if len(errs) != 0 {
+			ok = false
+		}
+	}
+	return ok, nil
+}
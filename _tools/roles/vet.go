@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	register(newAnalyzer("vet", findVet, VetReport))
+}
+
+var (
+	runStaticcheck = flag.Bool("staticcheck", false, "run staticcheck in addition to 'go vet' for the vet report (must be in PATH)")
+)
+
+// Finding is a single issue reported by a static analysis tool.
+type Finding struct {
+	// Check is the name of the vet analyzer or the staticcheck code.
+	Check string
+	// File is relative to the driver repository root, with forward slashes.
+	File    string
+	Line    int
+	Message string
+}
+
+// VetResult is the outcome of the static analysis of a driver.
+type VetResult struct {
+	Findings []Finding
+	// Errors are the failures of the tools. The driver is skipped if 'go vet'
+	// failed, e.g. because it cannot be built.
+	Errors []string
+}
+
+// findVet runs the static analysis tools over a driver. Drivers that cannot
+// be built are skipped with a warning, since those are usually legacy drivers
+// depending on an SDK version that is not installed. The errors are listed in
+// the report as well.
+func findVet(language, pkg string) (VetResult, error) {
+	dir, err := driverDir(pkg)
+	if err != nil {
+		return VetResult{}, err
+	}
+	var res VetResult
+	res.Findings, err = goVet(dir)
+	if err != nil {
+		log.Printf("warning: cannot vet %s driver: %v", language, err)
+		res.Errors = append(res.Errors, err.Error())
+		return res, nil
+	}
+	if *runStaticcheck {
+		more, err := staticcheck(dir)
+		if err != nil {
+			log.Printf("warning: cannot run staticcheck for %s driver: %v", language, err)
+			res.Errors = append(res.Errors, err.Error())
+		}
+		res.Findings = append(res.Findings, more...)
+	}
+	return res, nil
+}
+
+// goVet runs 'go vet' in the driver directory and returns its findings.
+func goVet(dir string) ([]Finding, error) {
+	cmd := exec.Command("go", "vet", "-json", "./...")
+	cmd.Dir = dir
+	// depending on the Go version, vet writes the findings either to stderr
+	// or to stdout; it succeeds with -json even if there are any, and build
+	// errors are written to stderr
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go vet: %v: %s", err, bytes.TrimSpace(out.Bytes()))
+	}
+	return parseVet(&out, dir)
+}
+
+// parseVet reads the output of 'go vet -json', that is a stream of JSON
+// objects keyed by package and analyzer, separated by comments with the
+// package names.
+func parseVet(r io.Reader, dir string) ([]Finding, error) {
+	var data bytes.Buffer
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if !strings.HasPrefix(sc.Text(), "#") {
+			data.WriteString(sc.Text() + "\n")
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	var list []Finding
+	dec := json.NewDecoder(&data)
+	for dec.More() {
+		var pkgs map[string]map[string]json.RawMessage
+		if err := dec.Decode(&pkgs); err != nil {
+			return nil, fmt.Errorf("go vet: %v", err)
+		}
+		for _, checks := range pkgs {
+			for check, raw := range checks {
+				// analyzer errors are reported as an object instead of a list
+				var diags []struct {
+					Posn    string `json:"posn"`
+					Message string `json:"message"`
+				}
+				if err := json.Unmarshal(raw, &diags); err != nil {
+					continue
+				}
+				for _, d := range diags {
+					file, line := splitPosn(d.Posn)
+					list = append(list, Finding{
+						Check: check, File: relPath(dir, file), Line: line, Message: d.Message,
+					})
+				}
+			}
+		}
+	}
+	return list, nil
+}
+
+// splitPosn splits the file:line:column position reported by vet.
+func splitPosn(posn string) (file string, line int) {
+	parts := strings.Split(posn, ":")
+	if len(parts) < 3 {
+		return posn, 0
+	}
+	file = strings.Join(parts[:len(parts)-2], ":")
+	line, _ = strconv.Atoi(parts[len(parts)-2])
+	return file, line
+}
+
+func relPath(dir, file string) string {
+	if rel, err := filepath.Rel(dir, file); err == nil {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
+
+// staticcheck runs staticcheck in the driver directory and returns its
+// findings.
+func staticcheck(dir string) ([]Finding, error) {
+	cmd := exec.Command("staticcheck", "-f", "json", "./...")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// staticcheck exits with an error if anything is found
+	if _, ok := err.(*exec.ExitError); err != nil && (!ok || len(out) == 0) {
+		return nil, fmt.Errorf("staticcheck: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	var list []Finding
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var d struct {
+			Code     string `json:"code"`
+			Location struct {
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"location"`
+			Message string `json:"message"`
+		}
+		if err := dec.Decode(&d); err != nil {
+			return nil, fmt.Errorf("staticcheck: %v", err)
+		}
+		list = append(list, Finding{
+			Check: d.Code, File: relPath(dir, d.Location.File), Line: d.Location.Line, Message: d.Message,
+		})
+	}
+	return list, nil
+}
+
+const vetHeader = "" +
+	"# Static analysis findings\n\n" +
+	"Issues reported by `go vet` (and staticcheck, if enabled) for each driver, " +
+	"grouped by check. Checks that fire for many drivers usually point to " +
+	"a misuse of the SDK API or to a problem in the generated code.\n\n"
+
+// VetReport renders the findings of all drivers grouped by check, the checks
+// found in most drivers first, followed by the errors of the tools.
+func VetReport(results map[string]VetResult) string {
+	byCheck := make(map[string]map[string][]Finding)
+	failed := false
+	for lang, res := range results {
+		failed = failed || len(res.Errors) != 0
+		for _, f := range res.Findings {
+			m := byCheck[f.Check]
+			if m == nil {
+				m = make(map[string][]Finding)
+				byCheck[f.Check] = m
+			}
+			m[lang] = append(m[lang], f)
+		}
+	}
+	total := func(m map[string][]Finding) int {
+		n := 0
+		for _, list := range m {
+			n += len(list)
+		}
+		return n
+	}
+	var checks []string
+	for check := range byCheck {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		a, b := byCheck[checks[i]], byCheck[checks[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		if total(a) != total(b) {
+			return total(a) > total(b)
+		}
+		return checks[i] < checks[j]
+	})
+
+	buf := bytes.NewBuffer([]byte(vetHeader))
+	switch {
+	case len(checks) == 0 && !failed:
+		buf.WriteString("No issues found.\n")
+		return buf.String()
+	case len(checks) == 0:
+		buf.WriteString("No issues found in the drivers that were analyzed, see the [errors](#errors) below.\n")
+		writeVetErrors(buf, results)
+		return buf.String()
+	}
+	buf.WriteString("Check|Drivers|Findings\n-|-|-\n")
+	for _, check := range checks {
+		fmt.Fprintf(buf, "[%s](#%s)|%d|%d\n", check, strings.ToLower(check), len(byCheck[check]), total(byCheck[check]))
+	}
+	for _, check := range checks {
+		fmt.Fprintf(buf, "\n## %s\n\n", check)
+		for _, lang := range languages() {
+			for _, f := range byCheck[check][lang] {
+				fmt.Fprintf(buf, "- %s: [`%s:%d`](%s): %s\n", strings.Title(lang), f.File, f.Line,
+					fmt.Sprintf(GitHubLinePattern, lang, f.File, f.Line), f.Message)
+			}
+		}
+	}
+	if failed {
+		writeVetErrors(buf, results)
+	}
+	return buf.String()
+}
+
+// writeVetErrors lists the errors of the tools for each driver, thus the
+// drivers that were skipped are not mistaken for the ones without issues.
+func writeVetErrors(buf *bytes.Buffer, results map[string]VetResult) {
+	buf.WriteString("\n## Errors\n\n" +
+		"The tools failed for those drivers, thus their findings are missing or incomplete.\n")
+	for _, lang := range languages() {
+		errs := results[lang].Errors
+		if len(errs) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "\n### %s\n\n```\n%s\n```\n", strings.Title(lang), strings.Join(errs, "\n"))
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseVet(t *testing.T) {
+	const dir = "/src/python-driver"
+	cases := []struct {
+		name string
+		out  string
+		want []Finding
+		err  bool
+	}{
+		{name: "empty", out: ""},
+		{
+			name: "no findings",
+			out: "# github.com/bblfsh/python-driver/driver\n" +
+				"{}\n",
+		},
+		{
+			name: "findings",
+			out: "# github.com/bblfsh/python-driver/driver/normalizer\n" +
+				"{\n" +
+				"\t\"github.com/bblfsh/python-driver/driver/normalizer\": {\n" +
+				"\t\t\"printf\": [\n" +
+				"\t\t\t{\"posn\": \"/src/python-driver/driver/normalizer/annotation.go:10:2\", \"message\": \"wrong type\"},\n" +
+				"\t\t\t{\"posn\": \"/src/python-driver/driver/normalizer/annotation.go:20:5\", \"message\": \"missing argument\"}\n" +
+				"\t\t]\n" +
+				"\t}\n" +
+				"}\n" +
+				"# github.com/bblfsh/python-driver/driver/fixtures\n" +
+				"{\"github.com/bblfsh/python-driver/driver/fixtures\": {\"unusedresult\": [{\"posn\": \"/src/python-driver/driver/fixtures/fixtures_test.go:5:1\", \"message\": \"result is not used\"}]}}\n",
+			want: []Finding{
+				{Check: "printf", File: "driver/normalizer/annotation.go", Line: 10, Message: "wrong type"},
+				{Check: "printf", File: "driver/normalizer/annotation.go", Line: 20, Message: "missing argument"},
+				{Check: "unusedresult", File: "driver/fixtures/fixtures_test.go", Line: 5, Message: "result is not used"},
+			},
+		},
+		{
+			// analyzer errors are reported as an object
+			name: "analyzer error",
+			out: "# github.com/bblfsh/python-driver/driver\n" +
+				"{\"github.com/bblfsh/python-driver/driver\": {\"printf\": {\"error\": \"analysis failed\"}}}\n",
+		},
+		{
+			name: "position without column",
+			out:  "{\"p\": {\"shift\": [{\"posn\": \"gen.go\", \"message\": \"too large\"}]}}\n",
+			want: []Finding{
+				{Check: "shift", File: "gen.go", Line: 0, Message: "too large"},
+			},
+		},
+		{name: "invalid", out: "# p\n{\"p\": \n", err: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseVet(strings.NewReader(tc.out), dir)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			// packages and checks of a single object are not ordered
+			sort.Slice(got, func(i, j int) bool {
+				a, b := got[i], got[j]
+				if a.Check != b.Check {
+					return a.Check < b.Check
+				} else if a.File != b.File {
+					return a.File < b.File
+				}
+				return a.Line < b.Line
+			})
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("unexpected findings:\n%+v\nwant:\n%+v", got, tc.want)
+			}
+		})
+	}
+}
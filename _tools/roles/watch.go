@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	watchDir = flag.String("watch", "", "watch a local driver checkout and print its coverage on each change")
+)
+
+// driverLanguage returns the language of a driver given its checkout
+// directory, i.e. python for python-driver.
+func driverLanguage(dir string) string {
+	name := filepath.Base(filepath.Clean(dir))
+	return strings.TrimSuffix(name, "-driver")
+}
+
+// modTimes returns the modification times of the normalizer sources and the
+// fixtures of a local driver checkout. Comparing the whole set, and not only
+// the latest time, catches the removed and renamed files as well.
+func modTimes(dir string) (map[string]time.Time, error) {
+	times := make(map[string]time.Time)
+	for _, pattern := range []string{
+		filepath.Join(dir, filepath.FromSlash(NormalizerDir), "*.go"),
+		filepath.Join(dir, fixturesDir(dir), "*"),
+	} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			fi, err := os.Stat(path)
+			if err != nil {
+				// file was removed while listing
+				continue
+			}
+			times[path] = fi.ModTime()
+		}
+	}
+	return times, nil
+}
+
+// sameModTimes checks if both sets list the same files with the same
+// modification times.
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if bt, ok := b[path]; !ok || !bt.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// runWatch polls a local driver checkout for changes and prints the roles
+// coverage of the driver each time its normalizer or fixtures change.
+func runWatch(dir string) error {
+	// a relative path without the "./" prefix, e.g. python-driver, would be
+	// taken for an import path and resolved against GOPATH instead of the
+	// working directory
+	dir, err := localDriver(dir)
+	if err != nil {
+		return err
+	}
+	lang := driverLanguage(dir)
+	roles, err := findRoles()
+	if err != nil {
+		return err
+	}
+	var last map[string]time.Time
+	for {
+		mod, err := modTimes(dir)
+		if err != nil {
+			return err
+		}
+		if last == nil || !sameModTimes(mod, last) {
+			last = mod
+			printCoverage(lang, dir, roles.clean())
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// printCoverage prints a short summary of the roles coverage of one driver.
+// Errors are printed as well, since the driver is most likely being edited.
+func printCoverage(lang, dir string, roles Roles) {
+	fmt.Printf("\n%s: %s\n", time.Now().Format("15:04:05"), lang)
+	if err := findUsage(lang, dir, roles); err != nil {
+		fmt.Println("annotations:", err)
+		return
+	}
+	unknown := make(UnknownRoles)
+	if err := findFixtureUsage(lang, dir, roles, unknown); err != nil {
+		fmt.Println("fixtures:", err)
+		return
+	}
+	var ann, fix int
+	for _, role := range roles {
+		if role.IsUsedBy(lang) {
+			ann++
+		}
+		if role.IsUsedInFixtures(lang) {
+			fix++
+		}
+	}
+	fmt.Printf("roles in annotations: %d/%d\n", ann, len(roles))
+	fmt.Printf("roles in fixtures:    %d/%d\n", fix, len(roles))
+	names := make([]string, 0, len(unknown[lang]))
+	for name := range unknown[lang] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("unknown role %s: %d times\n", name, len(unknown[lang][name]))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModTimes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "roles-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	norm := filepath.Join(dir, filepath.FromSlash(NormalizerDir))
+	fixtures := filepath.Join(dir, filepath.FromSlash(FixturesDir))
+	for _, d := range []string{norm, fixtures} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(norm, "annotation.go"),
+		filepath.Join(fixtures, "a.py.uast"),
+		filepath.Join(fixtures, "b.py.uast"),
+	} {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, err := modTimes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 3 {
+		t.Fatalf("expected 3 files, got %v", before)
+	}
+	same, err := modTimes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameModTimes(before, same) {
+		t.Error("unchanged files are reported as modified")
+	}
+	// removing a file does not change the latest modification time
+	if err := os.Remove(filepath.Join(fixtures, "a.py.uast")); err != nil {
+		t.Fatal(err)
+	}
+	after, err := modTimes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameModTimes(before, after) {
+		t.Error("removed file is not detected")
+	}
+}
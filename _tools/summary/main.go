@@ -0,0 +1,307 @@
+// Command summary updates the GitBook table of contents (SUMMARY.md) with
+// the pages found in the documentation tree.
+//
+// The existing structure of SUMMARY.md is kept: pages that are not listed yet
+// are added to the section that already lists most pages of the same
+// directory, and entries of removed pages are dropped.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	summaryFile = "SUMMARY.md"
+	indexFile   = "README.md"
+	// generatedMark is the first line of the generated index pages; pages
+	// without it are never overwritten
+	generatedMark = "<!-- Code generated by 'make summary' DO NOT EDIT. -->"
+)
+
+var (
+	root    = flag.String("root", ".", "root directory of the documentation")
+	write   = flag.Bool("w", false, "write the result to SUMMARY.md instead of the standard output")
+	exclude = flag.String("exclude", "proposals/drafts,uast/history", "comma-separated list of directories to skip")
+	index   = flag.Bool("index", false, "generate index pages for directories without a README.md")
+)
+
+var entryRe = regexp.MustCompile(`^\s*\* \[(.*)\]\((.*)\)\s*$`)
+
+// section is a part of the table of contents started by a heading.
+type section struct {
+	title string
+	// lines are kept as is to preserve manual formatting
+	lines []string
+}
+
+// page is a Markdown file in the documentation tree.
+type page struct {
+	// path is relative to the root and uses forward slashes, as in links
+	path  string
+	title string
+}
+
+func main() {
+	flag.Parse()
+
+	pages, err := findPages(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *index {
+		added, err := writeIndexes(*root, pages)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pages = append(pages, added...)
+		sort.Slice(pages, func(i, j int) bool {
+			return pages[i].path < pages[j].path
+		})
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(*root, summaryFile))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sections := parseSummary(data)
+	sections = updateSummary(sections, pages)
+
+	out := renderSummary(sections)
+	if !*write {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(*root, summaryFile), out, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// findPages lists all Markdown pages in the documentation tree, except the
+// ones in the excluded directories, build output and tools.
+func findPages(root string) ([]page, error) {
+	skip := make(map[string]bool)
+	for _, dir := range strings.Split(*exclude, ",") {
+		if dir != "" {
+			skip[path.Clean(dir)] = true
+		}
+	}
+	var pages []page
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		name := fi.Name()
+		if fi.IsDir() {
+			if rel != "." && (skip[rel] || strings.HasPrefix(name, ".") ||
+				strings.HasPrefix(name, "_") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(name) != ".md" || rel == summaryFile || rel == indexFile {
+			// root README is the introduction page of the book
+			return nil
+		}
+		title, err := pageTitle(p)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page{path: rel, title: title})
+		return nil
+	})
+	return pages, err
+}
+
+// pageTitle returns the first top-level heading of the page, or the file
+// name if there is none.
+func pageTitle(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:]), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(filepath.Base(p), ".md"), nil
+}
+
+// parseSummary splits the table of contents into sections. The first one
+// has no title and contains the entries before the first section heading.
+func parseSummary(data []byte) []*section {
+	sections := []*section{{}}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			sections = append(sections, &section{title: strings.TrimSpace(line[3:])})
+		}
+		last := sections[len(sections)-1]
+		last.lines = append(last.lines, line)
+	}
+	return sections
+}
+
+// entryLink returns the local path an entry links to, without the anchor.
+// It returns false for lines that are not entries and for external links.
+func entryLink(line string) (string, bool) {
+	m := entryRe.FindStringSubmatch(line)
+	if m == nil || strings.Contains(m[2], "://") {
+		return "", false
+	}
+	link := m[2]
+	if i := strings.Index(link, "#"); i >= 0 {
+		link = link[:i]
+	}
+	return path.Clean(link), true
+}
+
+// updateSummary removes entries of the pages that do not exist anymore and
+// adds entries for the pages that are not listed yet.
+func updateSummary(sections []*section, pages []page) []*section {
+	exists := make(map[string]bool, len(pages))
+	for _, p := range pages {
+		exists[p.path] = true
+	}
+	listed := make(map[string]bool)
+	// number of entries for each directory in each section
+	dirs := make(map[string]map[*section]int)
+	for _, s := range sections {
+		lines := s.lines[:0]
+		for _, line := range s.lines {
+			link, ok := entryLink(line)
+			if ok && !exists[link] && link != indexFile {
+				log.Println("removing entry for a missing page:", link)
+				continue
+			}
+			if ok {
+				listed[link] = true
+				dir := path.Dir(link)
+				if dirs[dir] == nil {
+					dirs[dir] = make(map[*section]int)
+				}
+				dirs[dir][s]++
+			}
+			lines = append(lines, line)
+		}
+		s.lines = lines
+	}
+
+	for _, p := range pages {
+		if listed[p.path] {
+			continue
+		}
+		dir := path.Dir(p.path)
+		s := sectionFor(sections, dirs[dir])
+		if s == nil {
+			s = &section{
+				title: strings.Title(path.Base(dir)),
+				lines: []string{"", "## " + strings.Title(path.Base(dir))},
+			}
+			sections = append(sections, s)
+			dirs[dir] = map[*section]int{s: 0}
+		}
+		log.Println("adding entry for a new page:", p.path)
+		s.add(fmt.Sprintf("* [%s](%s)", p.title, p.path))
+		dirs[dir][s]++
+	}
+	return sections
+}
+
+// sectionFor returns the section that lists most pages of a directory.
+// Sections are checked in order, thus the first one wins on ties.
+func sectionFor(sections []*section, counts map[*section]int) *section {
+	var (
+		best *section
+		max  int
+	)
+	for _, s := range sections {
+		if n, ok := counts[s]; ok && (best == nil || n > max) {
+			best, max = s, n
+		}
+	}
+	return best
+}
+
+// add inserts an entry after the last entry of the section.
+func (s *section) add(entry string) {
+	i := len(s.lines)
+	for j := len(s.lines) - 1; j >= 0; j-- {
+		if entryRe.MatchString(s.lines[j]) {
+			i = j + 1
+			break
+		}
+	}
+	if i == len(s.lines) && !entryRe.MatchString(s.lines[i-1]) {
+		// the first entry of the section
+		s.lines = append(s.lines, "")
+		i++
+	}
+	s.lines = append(s.lines[:i], append([]string{entry}, s.lines[i:]...)...)
+}
+
+func renderSummary(sections []*section) []byte {
+	buf := new(bytes.Buffer)
+	for _, s := range sections {
+		for _, line := range s.lines {
+			buf.WriteString(line + "\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeIndexes writes an index page for each directory that has no
+// README.md, or has one generated by this tool. It returns the pages that
+// were created.
+func writeIndexes(root string, pages []page) ([]page, error) {
+	byDir := make(map[string][]page)
+	for _, p := range pages {
+		if dir := path.Dir(p.path); dir != "." && path.Base(p.path) != indexFile {
+			byDir[dir] = append(byDir[dir], p)
+		}
+	}
+	var added []page
+	for dir, list := range byDir {
+		name := filepath.Join(root, filepath.FromSlash(dir), indexFile)
+		data, err := ioutil.ReadFile(name)
+		if err == nil && !bytes.HasPrefix(data, []byte(generatedMark)) {
+			// written by hand
+			continue
+		} else if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		title := strings.Title(path.Base(dir))
+		buf := bytes.NewBufferString(generatedMark + "\n\n# " + title + "\n\n")
+		for _, p := range list {
+			fmt.Fprintf(buf, "* [%s](%s)\n", p.title, path.Base(p.path))
+		}
+		if err := ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		if os.IsNotExist(err) {
+			added = append(added, page{path: path.Join(dir, indexFile), title: title})
+		}
+	}
+	return added, nil
+}